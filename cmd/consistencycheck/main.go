@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "remove the dangling references instead of only reporting them")
+	flag.Parse()
+
+	db, err := db.NewMongoDB()
+	if err != nil {
+		log.Fatal("cannot connect to database:", err)
+	}
+
+	report, err := db.CheckConsistency(*fix)
+	if err != nil {
+		log.Fatal("cannot check consistency:", err)
+	}
+
+	log.Println("missing problems in lists:", report.ListProblems)
+	log.Println("missing problems and lists in solve later:", report.SolveLater)
+	for collection, count := range report.Orphans {
+		log.Printf("orphaned documents in %s: %d", collection, count)
+	}
+	log.Println("attempts of missing problems (kept):", report.Attempts)
+	log.Println("problems with invalid labels:", report.ProblemLabels)
+	if *fix {
+		log.Println("dangling references fixed")
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"log"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+)
+
+func main() {
+	db, err := db.NewMongoDB()
+	if err != nil {
+		log.Fatal("cannot connect to database:", err)
+	}
+
+	err = db.DedupeLabels()
+	if err != nil {
+		log.Fatal("cannot dedupe labels:", err)
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupAchievementRoutes() {
+	server.router.GET("/api/users/:id/achievements", server.listUserAchievements)
+}
+
+func (server *Server) listUserAchievements(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	achievements, err := server.db.ListUserAchievements(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, achievements)
+}
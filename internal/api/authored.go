@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupAuthoredContentRoutes() {
+	userGroup := server.router.Group("/api/users")
+	{
+		userGroup.GET("/:id/authored", server.getAuthoredContent)
+	}
+}
+
+type getAuthoredContentRequest struct {
+	UserID   string `form:"user_id"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=30"`
+}
+
+func (server *Server) getAuthoredContent(ctx *gin.Context) {
+	var req getAuthoredContentRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pagination := db.PaginationParams{
+		Limit: req.PageSize,
+		Skip:  (req.PageID - 1) * req.PageSize,
+	}
+
+	content, err := server.db.GetAuthoredContent(ctx.Param("id"), req.UserID, pagination)
+	if err != nil {
+		if err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, content)
+}
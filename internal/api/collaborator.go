@@ -0,0 +1,155 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupCollaboratorRoutes() {
+	collaboratorGroup := server.router.Group("/api/problemlists/:id/collaborators")
+	{
+		collaboratorGroup.POST("", server.requireListRole(db.OwnerRole), server.inviteListCollaborator)
+		collaboratorGroup.POST("/accept", server.acceptListInvitation)
+		collaboratorGroup.DELETE("/:collaborator_id", server.removeListCollaborator)
+	}
+
+	server.router.GET("/api/users/:id/shared-lists", server.listSharedProblemLists)
+}
+
+type actingUserRequest struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+// requireListRole only lets the request through when the acting user, given by the user_id
+// query parameter, has at least the role on the list in the id path parameter.
+func (server *Server) requireListRole(role db.ListRole) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req actingUserRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		userRole, err := server.db.GetListRole(ctx.Param("id"), req.UserID)
+		if err != nil {
+			if err.Error() == "problem list not found" {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if !userRole.Includes(role) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errors.New("permission denied")))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func (server *Server) inviteListCollaborator(ctx *gin.Context) {
+	var arg db.InviteListCollaboratorParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	collaborator, err := server.db.InviteListCollaborator(arg, ctx.Param("id"))
+	if err != nil {
+		if err.Error() == "problem list not found" || err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if err.Error() == "user already collaborates on the list" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, collaborator)
+}
+
+func (server *Server) acceptListInvitation(ctx *gin.Context) {
+	var req actingUserRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.db.AcceptListInvitation(ctx.Param("id"), req.UserID); err != nil {
+		if err.Error() == "invitation not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (server *Server) removeListCollaborator(ctx *gin.Context) {
+	var req actingUserRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err := server.db.RemoveListCollaborator(ctx.Param("id"), ctx.Param("collaborator_id"), req.UserID)
+	if err != nil {
+		if err.Error() == "problem list not found" || err.Error() == "collaborator not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if err.Error() == "permission denied" {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+type listSharedProblemListsRequest struct {
+	PageID   int32                 `form:"page_id" binding:"required,min=1"`
+	PageSize int32                 `form:"page_size" binding:"required,min=5,max=30"`
+	Status   db.CollaboratorStatus `form:"status" binding:"omitempty,oneof=invited accepted"`
+}
+
+func (server *Server) listSharedProblemLists(ctx *gin.Context) {
+	var req listSharedProblemListsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListSharedProblemListsParams{
+		PaginationParams: db.PaginationParams{
+			Limit: req.PageSize,
+			Skip:  (req.PageID - 1) * req.PageSize,
+		},
+		UserID: ctx.Param("id"),
+		Status: req.Status,
+	}
+
+	problemLists, err := server.db.ListSharedProblemLists(arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemLists)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupExamRoutes() {
+	server.router.POST("/api/problemlists/:id/exam", server.requireListRole(db.EditorRole), server.setExamSettings)
+	server.router.DELETE("/api/problemlists/:id/exam", server.requireListRole(db.EditorRole), server.removeExamSettings)
+	server.router.POST("/api/problemlists/:id/exam-attempts", server.startExamAttempt)
+
+	examGroup := server.router.Group("/api/exam-attempts")
+	{
+		examGroup.GET("/:id", server.getExamAttempt)
+		examGroup.POST("/:id/answers", server.saveExamAnswer)
+		examGroup.POST("/:id/submit", server.submitExamAttempt)
+		examGroup.GET("/:id/report", server.getExamReport)
+	}
+}
+
+// examErrorStatus maps the errors shared by the exam endpoints to their status code.
+func examErrorStatus(err error) int {
+	switch err.Error() {
+	case "problem list not found", "exam attempt not found":
+		return http.StatusNotFound
+	case "problem list is not an exam",
+		"problem is not part of the exam",
+		"answer does not match the problem":
+		return http.StatusBadRequest
+	case "no exam attempts left",
+		"exam attempt is over",
+		"answer already revealed",
+		"exam attempt still in progress":
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+func (server *Server) setExamSettings(ctx *gin.Context) {
+	var arg db.SetExamSettingsParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err := server.db.SetExamSettings(arg, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (server *Server) removeExamSettings(ctx *gin.Context) {
+	err := server.db.RemoveExamSettings(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (server *Server) startExamAttempt(ctx *gin.Context) {
+	var arg db.StartExamAttemptParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	view, err := server.db.StartExamAttempt(arg, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+type examAttemptOwnerRequest struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+func (server *Server) getExamAttempt(ctx *gin.Context) {
+	var req examAttemptOwnerRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	view, err := server.db.GetExamAttempt(ctx.Param("id"), req.UserID)
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, view)
+}
+
+func (server *Server) saveExamAnswer(ctx *gin.Context) {
+	var arg db.SaveExamAnswerParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	saved, err := server.db.SaveExamAnswer(arg, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+func (server *Server) submitExamAttempt(ctx *gin.Context) {
+	var arg db.SubmitExamAttemptParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	report, err := server.db.SubmitExamAttempt(arg, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+func (server *Server) getExamReport(ctx *gin.Context) {
+	var req examAttemptOwnerRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	report, err := server.db.GetExamReport(ctx.Param("id"), req.UserID)
+	if err != nil {
+		ctx.JSON(examErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
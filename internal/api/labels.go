@@ -13,18 +13,27 @@ func (server *Server) setupLabelsRoutes() {
 		subjectGroup.POST("", server.createSubject)
 		subjectGroup.GET("/:id", server.getSubject)
 		subjectGroup.GET("", server.listSubjects)
+		subjectGroup.PATCH("/:id", server.renameLabel(db.SubjectLevel))
+		subjectGroup.DELETE("/:id", server.deleteLabel(db.SubjectLevel))
+		subjectGroup.POST("/:id/merge", server.mergeLabels(db.SubjectLevel))
 	}
 	topicGroup := server.router.Group("/api/topics")
 	{
 		topicGroup.POST("/create", server.createTopic)
 		topicGroup.GET("/:id", server.getTopic)
 		topicGroup.POST("/list", server.listTopics)
+		topicGroup.PATCH("/:id", server.renameLabel(db.TopicLevel))
+		topicGroup.DELETE("/:id", server.deleteLabel(db.TopicLevel))
+		topicGroup.POST("/:id/merge", server.mergeLabels(db.TopicLevel))
 	}
 	subtopicGroup := server.router.Group("/api/subtopics")
 	{
 		subtopicGroup.POST("/create", server.createSubtopic)
 		subtopicGroup.GET("/:id", server.getSubtopic)
 		subtopicGroup.POST("/list", server.listSubtopics)
+		subtopicGroup.PATCH("/:id", server.renameLabel(db.SubtopicLevel))
+		subtopicGroup.DELETE("/:id", server.deleteLabel(db.SubtopicLevel))
+		subtopicGroup.POST("/:id/merge", server.mergeLabels(db.SubtopicLevel))
 	}
 }
 
@@ -38,6 +47,11 @@ func (server *Server) createSubject(ctx *gin.Context) {
 
 	subject, err := server.db.CreateSubject(arg)
 	if err != nil {
+		if err.Error() == "subject already exists" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -77,6 +91,11 @@ func (server *Server) createTopic(ctx *gin.Context) {
 
 	topic, err := server.db.CreateTopic(arg)
 	if err != nil {
+		if err.Error() == "topic already exists" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -122,6 +141,11 @@ func (server *Server) createSubtopic(ctx *gin.Context) {
 
 	subtopic, err := server.db.CreateSubtopic(arg)
 	if err != nil {
+		if err.Error() == "subtopic already exists" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -156,3 +180,77 @@ func (server *Server) listSubtopics(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, subtopics)
 }
+
+// labelErrorStatus maps the errors of renaming, deleting and merging labels to their status code.
+func labelErrorStatus(err error) int {
+	switch err.Error() {
+	case "subject not found", "topic not found", "subtopic not found":
+		return http.StatusNotFound
+	case "subject already exists", "topic already exists", "subtopic already exists",
+		"subject is in use", "topic is in use", "subtopic is in use",
+		"merge would duplicate child labels":
+		return http.StatusConflict
+	case "cannot merge a label into itself", "merge target not found",
+		"cannot merge subjects of different languages":
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
+
+func (server *Server) renameLabel(level db.LabelLevel) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var arg db.RenameLabelParams
+		if err := ctx.ShouldBindJSON(&arg); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		if err := server.db.RenameLabel(level, ctx.Param("id"), arg); err != nil {
+			ctx.JSON(labelErrorStatus(err), errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, arg)
+	}
+}
+
+type deleteLabelRequest struct {
+	ReassignTo string `form:"reassign_to"`
+}
+
+// deleteLabel deletes an unused label, or merges it into the label given by reassign_to.
+func (server *Server) deleteLabel(level db.LabelLevel) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req deleteLabelRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		if err := server.db.DeleteLabel(level, ctx.Param("id"), req.ReassignTo); err != nil {
+			ctx.JSON(labelErrorStatus(err), errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusNoContent, gin.H{})
+	}
+}
+
+func (server *Server) mergeLabels(level db.LabelLevel) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var arg db.MergeLabelParams
+		if err := ctx.ShouldBindJSON(&arg); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		merge, err := server.db.MergeLabels(level, ctx.Param("id"), arg)
+		if err != nil {
+			ctx.JSON(labelErrorStatus(err), errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, merge)
+	}
+}
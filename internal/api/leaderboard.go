@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupLeaderboardRoutes() {
+	server.router.GET("/api/leaderboards", server.getLeaderboard)
+	server.router.POST("/api/users/:id/leaderboard-visibility", server.setLeaderboardVisibility)
+}
+
+func (server *Server) getLeaderboard(ctx *gin.Context) {
+	var arg db.GetLeaderboardParams
+	if err := ctx.ShouldBindQuery(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	leaderboard, err := server.db.GetLeaderboard(arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, leaderboard)
+}
+
+func (server *Server) setLeaderboardVisibility(ctx *gin.Context) {
+	var arg db.SetLeaderboardVisibilityParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id := ctx.Param("id")
+	if err := server.db.SetLeaderboardVisibility(arg, id); err != nil {
+		if err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, arg)
+}
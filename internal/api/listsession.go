@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupListSessionRoutes() {
+	server.router.POST("/api/problemlists/:id/sessions", server.startListSession)
+	server.router.GET("/api/list-sessions/:id", server.getListSession)
+	server.router.GET("/api/list-sessions/:id/summary", server.getListSessionSummary)
+}
+
+func (server *Server) startListSession(ctx *gin.Context) {
+	var arg db.StartListSessionParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	state, err := server.db.StartListSession(arg, ctx.Param("id"))
+	if err != nil {
+		if err.Error() == "problem list not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+func (server *Server) getListSession(ctx *gin.Context) {
+	state, err := server.db.GetListSession(ctx.Param("id"))
+	if err != nil {
+		if err.Error() == "list session not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+func (server *Server) getListSessionSummary(ctx *gin.Context) {
+	summary, err := server.db.GetListSessionSummary(ctx.Param("id"))
+	if err != nil {
+		if err.Error() == "list session not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupMistakeNotebookRoutes() {
+	userGroup := server.router.Group("/api/users")
+	{
+		userGroup.GET("/:id/mistakes", server.listMistakes)
+		userGroup.POST("/:id/mistakes/export", server.exportMistakes)
+	}
+}
+
+type listMistakesRequest struct {
+	PageID    int32  `form:"page_id" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=30"`
+	SubjectID string `form:"subject_id"`
+}
+
+func (server *Server) listMistakes(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	var req listMistakesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListMistakesParams{
+		PaginationParams: db.PaginationParams{
+			Limit: req.PageSize,
+			Skip:  (req.PageID - 1) * req.PageSize,
+		},
+		SubjectFilter: req.SubjectID,
+	}
+
+	mistakes, err := server.db.ListMistakes(userID, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mistakes)
+}
+
+func (server *Server) exportMistakes(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	var arg db.ExportMistakesParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	problemList, err := server.db.ExportMistakesToList(userID, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemList)
+}
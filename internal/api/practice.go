@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupPracticeRoutes() {
+	server.router.POST("/api/practice/next", server.nextPracticeProblem)
+}
+
+func (server *Server) nextPracticeProblem(ctx *gin.Context) {
+	var arg db.NextPracticeProblemParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	practice, err := server.db.NextPracticeProblem(arg)
+	if err != nil {
+		if err.Error() == "no problem to practice" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, practice)
+}
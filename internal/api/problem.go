@@ -40,7 +40,7 @@ func (server *Server) createTFProblem(ctx *gin.Context) {
 
 	problem, err := server.db.CreateTFProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -57,7 +57,7 @@ func (server *Server) createMTFProblem(ctx *gin.Context) {
 
 	problem, err := server.db.CreateMTFProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -74,7 +74,7 @@ func (server *Server) createMCProblem(ctx *gin.Context) {
 
 	problem, err := server.db.CreateMCProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -91,28 +91,50 @@ func (server *Server) createMSProblem(ctx *gin.Context) {
 
 	problem, err := server.db.CreateMSProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, problem)
 }
 
+type getProblemRequest struct {
+	UserID string `form:"user_id"`
+}
+
 func (server *Server) getProblem(ctx *gin.Context) {
+	var req getProblemRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	id := ctx.Param("id")
 
-	problem, err := server.db.GetProblem(id)
+	problem, err := server.db.GetVisibleProblem(id, req.UserID)
 	if err != nil {
+		if err.Error() == "problem not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, problem)
+	views, err := server.db.HideExamAnswers(req.UserID, []db.AnyProblem{problem})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, views[0])
 }
 
 type listProblemsRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=30"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=30"`
+	UserID   string `form:"user_id"`
 }
 
 func (server *Server) listProblems(ctx *gin.Context) {
@@ -142,7 +164,13 @@ func (server *Server) listProblems(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, problems)
+	views, err := server.db.HideExamAnswers(req.UserID, problems)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, views)
 }
 
 func (server *Server) updateProblem(ctx *gin.Context) {
@@ -154,12 +182,7 @@ func (server *Server) updateProblem(ctx *gin.Context) {
 
 	id := ctx.Param("id")
 	if err := server.db.UpdateProblem(arg, id); err != nil {
-		if err.Error() == "problem not found" {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -182,6 +205,41 @@ func (server *Server) deleteProblem(ctx *gin.Context) {
 	ctx.JSON(http.StatusNoContent, gin.H{})
 }
 
+// referenceErrorStatus maps the errors of writes that reference missing or inconsistent
+// labels and problems to their status code.
+func referenceErrorStatus(err error) int {
+	switch err.Error() {
+	case "subject not found", "topic not found", "subtopic not found",
+		"topic does not belong to subject", "subtopic does not belong to topic",
+		"list references unknown problems":
+		return http.StatusBadRequest
+	case "problem not found":
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
+
+// solveProblemErrorStatus maps the errors shared by every solve endpoint to their status code.
+func solveProblemErrorStatus(err error) int {
+	switch err.Error() {
+	case "attempt start token not found",
+		"attempt start token expired",
+		"list session already finished",
+		"problem is not the next one in the list session",
+		"response does not match the problem":
+		return http.StatusBadRequest
+	case "problem not found", "list session not found":
+		return http.StatusNotFound
+	case "problem is part of an exam in progress":
+		return http.StatusConflict
+	case "attempt cooldown active":
+		return http.StatusTooManyRequests
+	}
+
+	return http.StatusInternalServerError
+}
+
 func (server *Server) solveTFProblem(ctx *gin.Context) {
 	var arg db.SolveTFProblemParams
 	if err := ctx.ShouldBindJSON(&arg); err != nil {
@@ -191,7 +249,7 @@ func (server *Server) solveTFProblem(ctx *gin.Context) {
 
 	result, err := server.db.SolveTFProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(solveProblemErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -207,7 +265,7 @@ func (server *Server) solveMTFProblem(ctx *gin.Context) {
 
 	result, err := server.db.SolveMTFProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(solveProblemErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -223,7 +281,7 @@ func (server *Server) solveMCProblem(ctx *gin.Context) {
 
 	result, err := server.db.SolveMCProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(solveProblemErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -239,7 +297,7 @@ func (server *Server) solveMSProblem(ctx *gin.Context) {
 
 	result, err := server.db.SolveMSProblem(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(solveProblemErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -275,7 +333,7 @@ func (server *Server) reportProblem(ctx *gin.Context) {
 
 	report, err := server.db.CreateProblemReport(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Tuzi07/solvify-backend/internal/db"
 	"github.com/gin-gonic/gin"
@@ -9,6 +14,13 @@ import (
 
 func (server *Server) setupProblemAttemptRoutes() {
 	server.router.GET("/api/problem-attempt/:id", server.listUserAttempts)
+	server.router.GET("/api/users/:id/attempts/export", server.exportUserAttempts)
+
+	problemGroup := server.router.Group("/api/problems")
+	{
+		problemGroup.POST("/:id/start", server.startProblemAttempt)
+		problemGroup.GET("/:id/analytics", server.getProblemAnalytics)
+	}
 }
 
 type listAttemptsRequest struct {
@@ -38,3 +50,147 @@ func (server *Server) listUserAttempts(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, attempts)
 }
+
+func (server *Server) startProblemAttempt(ctx *gin.Context) {
+	var arg db.StartProblemAttemptParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	problemID := ctx.Param("id")
+	start, err := server.db.StartProblemAttempt(arg, problemID)
+	if err != nil {
+		if err.Error() == "problem not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, start)
+}
+
+func (server *Server) getProblemAnalytics(ctx *gin.Context) {
+	problemID := ctx.Param("id")
+
+	analytics, err := server.db.GetProblemAnalytics(problemID, ctx.Query("user_id"))
+	if err != nil {
+		if err.Error() == "problem not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+type exportAttemptsRequest struct {
+	Format string `form:"format" binding:"required,export_format"`
+}
+
+var attemptExportHeader = []string{
+	"attempt_id", "attempted_at", "problem_id", "problem_type", "statement",
+	"subject", "topic", "subtopic", "response", "solution_accuracy", "duration_ms",
+}
+
+// exportUserAttempts streams the attempt history of a user as csv or json lines.
+// The response starts with the first row, so an error before it still gets a proper status.
+// Later errors can only end the stream.
+func (server *Server) exportUserAttempts(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	var req exportAttemptsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	csvWriter := csv.NewWriter(ctx.Writer)
+	jsonEncoder := json.NewEncoder(ctx.Writer)
+
+	started := false
+	start := func() error {
+		started = true
+		ctx.Header("Content-Disposition", "attachment; filename=attempts."+req.Format)
+		if req.Format == "jsonl" {
+			ctx.Header("Content-Type", "application/x-ndjson")
+			ctx.Status(http.StatusOK)
+			return nil
+		}
+
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Status(http.StatusOK)
+		return csvWriter.Write(attemptExportHeader)
+	}
+
+	write := func(row db.AttemptExportRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if req.Format == "jsonl" {
+			if err := jsonEncoder.Encode(row); err != nil {
+				return err
+			}
+		} else {
+			record, err := attemptExportRecord(row)
+			if err != nil {
+				return err
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+
+		ctx.Writer.Flush()
+		return nil
+	}
+
+	err := server.db.ExportUserAttempts(userID, write)
+	if err != nil {
+		if !started {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		} else {
+			log.Println("cannot export attempts:", err)
+		}
+		return
+	}
+
+	if !started {
+		start()
+		csvWriter.Flush()
+	}
+}
+
+func attemptExportRecord(row db.AttemptExportRow) ([]string, error) {
+	response, err := json.Marshal(row.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		row.AttemptID,
+		row.AttemptedAt.Format(time.RFC3339),
+		row.ProblemID,
+		row.ProblemType,
+		row.Statement,
+		row.Subject,
+		row.Topic,
+		row.Subtopic,
+		string(response),
+		row.SolutionAccuracy,
+		strconv.FormatInt(row.DurationMs, 10),
+	}, nil
+}
@@ -31,7 +31,7 @@ func (server *Server) createProblemEditSuggestion(ctx *gin.Context) {
 
 	suggestion, err := server.db.CreateProblemEditSuggestion(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -72,7 +72,7 @@ func (server *Server) acceptProblemEditSuggestion(ctx *gin.Context) {
 	problem, err := server.db.AcceptProblemEditSuggestion(id)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -82,6 +82,16 @@ func (server *Server) acceptProblemEditSuggestion(ctx *gin.Context) {
 func (server *Server) listSuggestionsOfProblem(ctx *gin.Context) {
 	id := ctx.Param("id")
 
+	if _, err := server.db.GetVisibleProblem(id, ctx.Query("user_id")); err != nil {
+		if err.Error() == "problem not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	suggestions, err := server.db.ListSuggestionsOfProblem(id)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -13,8 +13,21 @@ func (server *Server) setupProblemListRoutes() {
 		problemGroup.POST("", server.createProblemList)
 		problemGroup.GET("/:id", server.getProblemList)
 		problemGroup.GET("", server.listProblemLists)
-		problemGroup.POST("/:id", server.updateProblemList)
-		problemGroup.DELETE("/:id", server.deleteProblemList)
+		problemGroup.POST("/:id", server.requireListRole(db.EditorRole), server.updateProblemList)
+		problemGroup.PATCH("/:id", server.requireListRole(db.EditorRole), server.updateProblemList)
+		problemGroup.DELETE("/:id", server.requireListRole(db.OwnerRole), server.deleteProblemList)
+
+		problemGroup.POST("/:id/problems", server.requireListRole(db.EditorRole), server.addProblemToList)
+		problemGroup.DELETE("/:id/problems/:problem_id", server.requireListRole(db.EditorRole), server.removeProblemFromList)
+		problemGroup.PATCH("/:id/problems/:problem_id", server.requireListRole(db.EditorRole), server.moveProblemInList)
+		problemGroup.POST("/:id/freeze", server.requireListRole(db.EditorRole), server.freezeProblemList)
+		problemGroup.POST("/:id/fork", server.forkProblemList)
+
+		problemGroup.POST("/vote", server.voteProblemList)
+		problemGroup.GET("/:id/vote", server.getProblemListVote)
+
+		problemGroup.POST("/report", server.reportProblemList)
+		problemGroup.GET("/reported", server.listReportedProblemLists)
 	}
 }
 
@@ -28,18 +41,50 @@ func (server *Server) createProblemList(ctx *gin.Context) {
 
 	problemList, err := server.db.CreateProblemList(arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, problemList)
 }
 
+type getProblemListRequest struct {
+	Expand string `form:"expand" binding:"omitempty,oneof=problems"`
+	UserID string `form:"user_id"`
+}
+
 func (server *Server) getProblemList(ctx *gin.Context) {
+	var req getProblemListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	id := ctx.Param("id")
 
-	problemList, err := server.db.GetProblemList(id)
+	if req.Expand == "problems" {
+		hydrated, err := server.db.GetHydratedProblemList(id, req.UserID)
+		if err != nil {
+			if err.Error() == "problem list not found" {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, hydrated)
+		return
+	}
+
+	problemList, err := server.db.GetVisibleProblemList(id, req.UserID)
 	if err != nil {
+		if err.Error() == "problem list not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -59,7 +104,13 @@ func (server *Server) listProblemLists(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListProblemListsParams{
+	var arg db.ListProblemListsParams
+	if err := ctx.ShouldBindQuery(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg.PaginationParams = db.PaginationParams{
 		Limit: req.PageSize,
 		Skip:  (req.PageID - 1) * req.PageSize,
 	}
@@ -86,8 +137,16 @@ func (server *Server) updateProblemList(ctx *gin.Context) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
+		if err.Error() == "no fields to update" {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if err.Error() == "dynamic list cannot be edited" || err.Error() == "problem list is not dynamic" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(referenceErrorStatus(err), errorResponse(err))
 		return
 	}
 
@@ -109,3 +168,189 @@ func (server *Server) deleteProblemList(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNoContent, gin.H{})
 }
+
+// listEditErrorStatus maps the errors of the list editing operations to their status code.
+func listEditErrorStatus(err error) int {
+	switch err.Error() {
+	case "problem list not found", "problem not found", "problem not in list":
+		return http.StatusNotFound
+	case "problem already in list", "dynamic list cannot be edited":
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+func (server *Server) addProblemToList(ctx *gin.Context) {
+	var arg db.AddListProblemParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	problemList, err := server.db.AddProblemToList(arg, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(listEditErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemList)
+}
+
+func (server *Server) removeProblemFromList(ctx *gin.Context) {
+	problemList, err := server.db.RemoveProblemFromList(ctx.Param("id"), ctx.Param("problem_id"))
+	if err != nil {
+		ctx.JSON(listEditErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemList)
+}
+
+func (server *Server) moveProblemInList(ctx *gin.Context) {
+	var arg db.MoveListProblemParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	problemList, err := server.db.MoveProblemInList(arg, ctx.Param("id"), ctx.Param("problem_id"))
+	if err != nil {
+		ctx.JSON(listEditErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemList)
+}
+
+func (server *Server) freezeProblemList(ctx *gin.Context) {
+	problemList, err := server.db.FreezeProblemList(ctx.Param("id"))
+	if err != nil {
+		if err.Error() == "problem list not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if err.Error() == "problem list is not dynamic" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemList)
+}
+
+func (server *Server) forkProblemList(ctx *gin.Context) {
+	var arg db.ForkProblemListParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	problemList, err := server.db.ForkProblemList(arg, ctx.Param("id"))
+	if err != nil {
+		if err.Error() == "problem list not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemList)
+}
+
+func (server *Server) voteProblemList(ctx *gin.Context) {
+	var arg db.VoteProblemListParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.db.VoteProblemList(arg); err != nil {
+		if err.Error() == "problem list not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, arg)
+}
+
+type getProblemListVoteRequest struct {
+	UserID string `form:"user_id" binding:"required"`
+}
+
+func (server *Server) getProblemListVote(ctx *gin.Context) {
+	var req getProblemListVoteRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id := ctx.Param("id")
+	voteStatus, err := server.db.GetProblemListVote(id, req.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"vote_status": voteStatus})
+}
+
+func (server *Server) reportProblemList(ctx *gin.Context) {
+	var arg db.ReportProblemListParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	report, err := server.db.CreateListReport(arg)
+	if err != nil {
+		if err.Error() == "problem list not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if err.Error() == "problem list already reported by user" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+type listReportedProblemListsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=30"`
+}
+
+func (server *Server) listReportedProblemLists(ctx *gin.Context) {
+	var req listReportedProblemListsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pagination := db.PaginationParams{
+		Limit: req.PageSize,
+		Skip:  (req.PageID - 1) * req.PageSize,
+	}
+
+	reportedLists, err := server.db.ListReportedProblemLists(pagination)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reportedLists)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupReviewRoutes() {
+	server.router.GET("/api/users/:id/review-queue", server.listReviewQueue)
+}
+
+func (server *Server) listReviewQueue(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	var arg db.ReviewQueueParams
+	if err := ctx.ShouldBindQuery(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	problems, err := server.db.ListReviewQueue(userID, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problems)
+}
@@ -27,7 +27,16 @@ func (server *Server) buildAPIRouter() {
 		v.RegisterValidation("language", validLanguage)
 		v.RegisterValidation("languages", validLanguages)
 		v.RegisterValidation("field_to_order_problems", validFieldToOrderProblems)
+		v.RegisterValidation("field_to_order_lists", validFieldToOrderLists)
 		v.RegisterValidation("level_of_education", validLevelOfEducation)
+		v.RegisterValidation("leaderboard_scope", validLeaderboardScope)
+		v.RegisterValidation("leaderboard_window", validLeaderboardWindow)
+		v.RegisterValidation("leaderboard_metric", validLeaderboardMetric)
+		v.RegisterValidation("daily_goal_type", validDailyGoalType)
+		v.RegisterValidation("export_format", validExportFormat)
+		v.RegisterValidation("reveal_answers", validRevealAnswers)
+		v.RegisterValidation("collaborator_role", validCollaboratorRole)
+		v.RegisterValidation("visibility", validVisibility)
 	}
 
 	config := cors.DefaultConfig()
@@ -40,6 +49,17 @@ func (server *Server) buildAPIRouter() {
 	server.setupLabelsRoutes()
 	server.setupProblemEditSuggestionsRoutes()
 	server.setupProblemAttemptRoutes()
+	server.setupReviewRoutes()
+	server.setupMistakeNotebookRoutes()
+	server.setupLeaderboardRoutes()
+	server.setupStreakRoutes()
+	server.setupAchievementRoutes()
+	server.setupPracticeRoutes()
+	server.setupListSessionRoutes()
+	server.setupExamRoutes()
+	server.setupCollaboratorRoutes()
+	server.setupSolveLaterRoutes()
+	server.setupAuthoredContentRoutes()
 }
 
 func (server *Server) Start() error {
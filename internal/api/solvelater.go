@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupSolveLaterRoutes() {
+	userGroup := server.router.Group("/api/users")
+	{
+		userGroup.GET("/:id/solve-later/problems", server.listSolveLaterProblems)
+		userGroup.POST("/:id/solve-later/problems", server.addSolveLaterProblem)
+		userGroup.DELETE("/:id/solve-later/problems/:problem_id", server.removeSolveLaterProblem)
+
+		userGroup.GET("/:id/solve-later/lists", server.listSolveLaterLists)
+		userGroup.POST("/:id/solve-later/lists", server.addSolveLaterList)
+		userGroup.DELETE("/:id/solve-later/lists/:list_id", server.removeSolveLaterList)
+	}
+}
+
+// solveLaterErrorStatus maps the errors of the bookmark endpoints to their status code.
+func solveLaterErrorStatus(err error) int {
+	switch err.Error() {
+	case "user not found", "problem not found", "problem list not found":
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
+
+type listSolveLaterRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=30"`
+}
+
+type addSolveLaterProblemRequest struct {
+	ProblemID string `json:"problem_id" binding:"required"`
+}
+
+type addSolveLaterListRequest struct {
+	ListID string `json:"list_id" binding:"required"`
+}
+
+func (server *Server) listSolveLaterProblems(ctx *gin.Context) {
+	var req listSolveLaterRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pagination := db.PaginationParams{
+		Limit: req.PageSize,
+		Skip:  (req.PageID - 1) * req.PageSize,
+	}
+
+	bookmarks, err := server.db.ListSolveLaterProblems(ctx.Param("id"), pagination)
+	if err != nil {
+		ctx.JSON(solveLaterErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bookmarks)
+}
+
+func (server *Server) addSolveLaterProblem(ctx *gin.Context) {
+	var req addSolveLaterProblemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.db.AddSolveLaterProblem(ctx.Param("id"), req.ProblemID); err != nil {
+		ctx.JSON(solveLaterErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (server *Server) removeSolveLaterProblem(ctx *gin.Context) {
+	if err := server.db.RemoveSolveLaterProblem(ctx.Param("id"), ctx.Param("problem_id")); err != nil {
+		ctx.JSON(solveLaterErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (server *Server) listSolveLaterLists(ctx *gin.Context) {
+	var req listSolveLaterRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pagination := db.PaginationParams{
+		Limit: req.PageSize,
+		Skip:  (req.PageID - 1) * req.PageSize,
+	}
+
+	problemLists, err := server.db.ListSolveLaterLists(ctx.Param("id"), pagination)
+	if err != nil {
+		ctx.JSON(solveLaterErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, problemLists)
+}
+
+func (server *Server) addSolveLaterList(ctx *gin.Context) {
+	var req addSolveLaterListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.db.AddSolveLaterList(ctx.Param("id"), req.ListID); err != nil {
+		ctx.JSON(solveLaterErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
+
+func (server *Server) removeSolveLaterList(ctx *gin.Context) {
+	if err := server.db.RemoveSolveLaterList(ctx.Param("id"), ctx.Param("list_id")); err != nil {
+		ctx.JSON(solveLaterErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, gin.H{})
+}
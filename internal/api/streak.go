@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Tuzi07/solvify-backend/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) setupStreakRoutes() {
+	userGroup := server.router.Group("/api/users")
+	{
+		userGroup.GET("/:id/streak", server.getStreak)
+		userGroup.POST("/:id/daily-goal", server.setDailyGoal)
+	}
+}
+
+func (server *Server) getStreak(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	streak, err := server.db.GetStreak(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, streak)
+}
+
+func (server *Server) setDailyGoal(ctx *gin.Context) {
+	var arg db.SetDailyGoalParams
+	if err := ctx.ShouldBindJSON(&arg); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id := ctx.Param("id")
+	if err := server.db.SetDailyGoal(arg, id); err != nil {
+		if err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, arg)
+}
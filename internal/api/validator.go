@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/Tuzi07/solvify-backend/internal/db"
 	"github.com/Tuzi07/solvify-backend/internal/util"
 	"github.com/go-playground/validator/v10"
 )
@@ -31,9 +32,72 @@ var validFieldToOrderProblems validator.Func = func(fieldLevel validator.FieldLe
 	return false
 }
 
+var validFieldToOrderLists validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if field, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsFieldToOrderLists(field)
+	}
+	return false
+}
+
 var validLevelOfEducation validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	if level, ok := fieldLevel.Field().Interface().(string); ok {
 		return util.IsLevelOfEducation(level)
 	}
 	return false
 }
+
+var validLeaderboardScope validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if scope, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsLeaderboardScope(scope)
+	}
+	return false
+}
+
+var validLeaderboardWindow validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if window, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsLeaderboardWindow(window)
+	}
+	return false
+}
+
+var validLeaderboardMetric validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if metric, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsLeaderboardMetric(metric)
+	}
+	return false
+}
+
+var validDailyGoalType validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if goalType, ok := fieldLevel.Field().Interface().(db.DailyGoalType); ok {
+		return util.IsDailyGoalType(string(goalType))
+	}
+	return false
+}
+
+var validExportFormat validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if format, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsExportFormat(format)
+	}
+	return false
+}
+
+var validRevealAnswers validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if reveal, ok := fieldLevel.Field().Interface().(db.RevealAnswers); ok {
+		return util.IsRevealAnswers(string(reveal))
+	}
+	return false
+}
+
+var validCollaboratorRole validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if role, ok := fieldLevel.Field().Interface().(db.ListRole); ok {
+		return util.IsCollaboratorRole(string(role))
+	}
+	return false
+}
+
+var validVisibility validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if visibility, ok := fieldLevel.Field().Interface().(db.Visibility); ok {
+		return util.IsVisibility(string(visibility))
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AchievementDatabase interface {
+	ListUserAchievements(userID string) ([]UserAchievement, error)
+}
+
+type AchievementEvent string
+
+const (
+	ProblemSolvedEvent      AchievementEvent = "problem_solved"
+	ProblemVotedEvent       AchievementEvent = "problem_voted"
+	ListCreatedEvent        AchievementEvent = "list_created"
+	ListVotedEvent          AchievementEvent = "list_voted"
+	SuggestionAcceptedEvent AchievementEvent = "suggestion_accepted"
+)
+
+// AchievementRule is a badge definition read from the achievements file.
+// The badge is awarded when the metric reaches the threshold after one of the events.
+type AchievementRule struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Events      []AchievementEvent `json:"events"`
+	Metric      string             `json:"metric"`
+	Threshold   float64            `json:"threshold"`
+
+	// MinAttempts and SubjectID are only used by the subject_accuracy metric.
+	// An empty SubjectID matches any subject.
+	MinAttempts int    `json:"min_attempts"`
+	SubjectID   string `json:"subject_id"`
+}
+
+func achievementsFile() string {
+	if file := os.Getenv("ACHIEVEMENTS_FILE"); file != "" {
+		return file
+	}
+	return "achievements.json"
+}
+
+// loadAchievementRules reads the rules once and indexes them by the events they listen to,
+// so a change to the achievements file takes effect on the next restart.
+func (db *MongoDB) loadAchievementRules() error {
+	content, err := os.ReadFile(achievementsFile())
+	if err != nil {
+		return err
+	}
+
+	var rules []AchievementRule
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return err
+	}
+
+	db.achievementRules = make(map[AchievementEvent][]AchievementRule)
+	for _, rule := range rules {
+		for _, event := range rule.Events {
+			db.achievementRules[event] = append(db.achievementRules[event], rule)
+		}
+	}
+	return nil
+}
+
+// evaluateAchievements awards the user every badge whose rule listens to the event and is now satisfied.
+// Achievements never make the operation that raised the event fail, so errors are only logged.
+func (db *MongoDB) evaluateAchievements(userID string, event AchievementEvent) {
+	if userID == "" {
+		return
+	}
+
+	if err := db.awardAchievements(userID, event); err != nil {
+		log.Println("cannot evaluate achievements:", err)
+	}
+}
+
+// awardAchievements only evaluates the rules listening to the event that the user has not earned yet,
+// and computes each metric at most once, since several rules can share it with different thresholds.
+func (db *MongoDB) awardAchievements(userID string, event AchievementEvent) error {
+	rules := db.achievementRules[event]
+	if len(rules) == 0 {
+		return nil
+	}
+
+	earned, err := db.earnedAchievementIDs(userID)
+	if err != nil {
+		return err
+	}
+
+	metrics := make(map[string]float64)
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_achievements")
+	for _, rule := range rules {
+		if earned[rule.ID] {
+			continue
+		}
+
+		satisfied, err := db.achievementRuleSatisfied(userID, rule, metrics)
+		if err != nil {
+			return err
+		}
+		if !satisfied {
+			continue
+		}
+
+		filter := bson.M{"user_id": userID, "achievement_id": rule.ID}
+		update := bson.M{"$setOnInsert": UserAchievement{
+			UserID:        userID,
+			AchievementID: rule.ID,
+			Name:          rule.Name,
+			Description:   rule.Description,
+			EarnedAt:      time.Now(),
+		}}
+		options := options.Update().SetUpsert(true)
+		if _, err := collection.UpdateOne(context.Background(), filter, update, options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *MongoDB) earnedAchievementIDs(userID string) (map[string]bool, error) {
+	achievements, err := db.ListUserAchievements(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	earned := make(map[string]bool)
+	for _, achievement := range achievements {
+		earned[achievement.AchievementID] = true
+	}
+	return earned, nil
+}
+
+// achievementRuleSatisfied checks the rule, reusing the metrics already computed for the user.
+func (db *MongoDB) achievementRuleSatisfied(userID string, rule AchievementRule, metrics map[string]float64) (bool, error) {
+	if rule.Metric == "subject_accuracy" {
+		return db.subjectAccuracyReached(userID, rule)
+	}
+
+	value, computed := metrics[rule.Metric]
+	if !computed {
+		var err error
+		value, err = db.achievementMetric(userID, rule.Metric)
+		if err != nil {
+			return false, err
+		}
+		metrics[rule.Metric] = value
+	}
+
+	return value >= rule.Threshold, nil
+}
+
+// achievementMetric returns the current value of a metric for the user.
+// The metric can be one of the following values: "problems_solved", "attempts", "accepted_suggestions",
+// "created_lists", "list_upvotes", "problem_upvotes", "longest_streak"
+func (db *MongoDB) achievementMetric(userID string, metric string) (float64, error) {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	switch metric {
+	case "problems_solved":
+		filter := bson.M{"user_id": userID, "solution_accuracy": Correct}
+		problemIDs, err := database.Collection("problem_attempts").Distinct(context.Background(), "problem_id", filter)
+		return float64(len(problemIDs)), err
+	case "attempts":
+		count, err := database.Collection("problem_attempts").CountDocuments(context.Background(), bson.M{"user_id": userID})
+		return float64(count), err
+	case "created_lists":
+		count, err := database.Collection("problemlists").CountDocuments(context.Background(), bson.M{"creator_id": userID})
+		return float64(count), err
+	case "list_upvotes":
+		var list ProblemList
+		err := db.mostUpvoted(database.Collection("problemlists"), userID, &list)
+		return float64(list.Upvotes), err
+	case "problem_upvotes":
+		var problem Problem
+		err := db.mostUpvoted(database.Collection("problems"), userID, &problem)
+		return float64(problem.Upvotes), err
+	case "accepted_suggestions":
+		user, err := db.GetUser(userID)
+		return float64(user.AcceptedSuggestions), err
+	case "longest_streak":
+		user, err := db.GetUser(userID)
+		return float64(user.Streak.Longest), err
+	}
+
+	return 0, errors.New("unknown achievement metric: " + metric)
+}
+
+// mostUpvoted decodes the most upvoted document created by the user, leaving the result empty if there is none.
+func (db *MongoDB) mostUpvoted(collection *mongo.Collection, userID string, result interface{}) error {
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "upvotes", Value: -1}})
+	err := collection.FindOne(context.Background(), bson.M{"creator_id": userID}, findOptions).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	return err
+}
+
+func (db *MongoDB) subjectAccuracyReached(userID string, rule AchievementRule) (bool, error) {
+	match := bson.M{"user_id": userID, "subject_id": bson.M{"$ne": ""}}
+	if rule.SubjectID != "" {
+		match["subject_id"] = rule.SubjectID
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$subject_id",
+			"attempts": bson.M{"$sum": 1},
+			"correct_answers": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$solution_accuracy", Correct}}, 1, 0},
+			}},
+		}}},
+		{{Key: "$match", Value: bson.M{"attempts": bson.M{"$gte": rule.MinAttempts}}}},
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
+	cursor, err := collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return false, err
+	}
+
+	var subjects []struct {
+		Attempts       int `bson:"attempts"`
+		CorrectAnswers int `bson:"correct_answers"`
+	}
+	if err := cursor.All(context.Background(), &subjects); err != nil {
+		return false, err
+	}
+
+	for _, subject := range subjects {
+		if subject.Attempts > 0 && float64(subject.CorrectAnswers)/float64(subject.Attempts) >= rule.Threshold {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (db *MongoDB) ListUserAchievements(userID string) ([]UserAchievement, error) {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_achievements")
+	findOptions := options.Find().SetSort(bson.D{{Key: "earned_at", Value: -1}})
+	cursor, err := collection.Find(context.Background(), bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	achievements := make([]UserAchievement, 0)
+	err = cursor.All(context.Background(), &achievements)
+	return achievements, err
+}
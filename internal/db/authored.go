@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuthoredContentDatabase interface {
+	GetAuthoredContent(authorID string, viewerID string, pagination PaginationParams) (AuthoredContent, error)
+	RebuildCreatedContent() error
+}
+
+// AuthoredContent is a page of the problems and lists created by a user, most recent first,
+// along with stats over everything the user created.
+type AuthoredContent struct {
+	Problems []StrippedProblem `json:"problems"`
+	Lists    []ProblemList     `json:"lists"`
+	Stats    AuthoredStats     `json:"stats"`
+}
+
+type AuthoredStats struct {
+	Problems        int     `json:"problems" bson:"problems"`
+	ProblemAttempts int     `json:"problem_attempts" bson:"problem_attempts"`
+	CorrectAnswers  int     `json:"correct_answers" bson:"correct_answers"`
+	Accuracy        float64 `json:"accuracy" bson:"-"`
+	ProblemUpvotes  int     `json:"problem_upvotes" bson:"problem_upvotes"`
+
+	Lists       int `json:"lists" bson:"lists"`
+	ListUpvotes int `json:"list_upvotes" bson:"list_upvotes"`
+	ListForks   int `json:"list_forks" bson:"list_forks"`
+}
+
+// GetAuthoredContent returns the content created by the author. Only the author sees
+// their unlisted and private content, everyone else only sees what is public.
+func (db *MongoDB) GetAuthoredContent(authorID string, viewerID string, pagination PaginationParams) (AuthoredContent, error) {
+	content := AuthoredContent{
+		Problems: make([]StrippedProblem, 0),
+		Lists:    make([]ProblemList, 0),
+	}
+
+	if _, err := db.GetUser(authorID); err != nil {
+		if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+			return content, errors.New("user not found")
+		}
+		return content, err
+	}
+
+	filter := bson.M{"creator_id": authorID}
+	if viewerID != authorID {
+		filter["visibility"] = listedVisibility()
+	}
+
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(pagination.Limit)).
+		SetSkip(int64(pagination.Skip))
+
+	cursor, err := database.Collection("problems").Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return content, err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var problem AnyProblem
+		if err := cursor.Decode(&problem); err != nil {
+			return content, err
+		}
+		content.Problems = append(content.Problems, strippedProblem(problem))
+	}
+	if err := cursor.Err(); err != nil {
+		return content, err
+	}
+
+	listCursor, err := database.Collection("problemlists").Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return content, err
+	}
+	defer listCursor.Close(context.Background())
+
+	for listCursor.Next(context.Background()) {
+		var list ProblemList
+		if err := listCursor.Decode(&list); err != nil {
+			return content, err
+		}
+		if err := db.resolveDynamicList(&list); err != nil {
+			return content, err
+		}
+		content.Lists = append(content.Lists, list)
+	}
+	if err := listCursor.Err(); err != nil {
+		return content, err
+	}
+
+	content.Stats, err = db.authoredStats(filter)
+
+	return content, err
+}
+
+func (db *MongoDB) authoredStats(filter bson.M) (AuthoredStats, error) {
+	var stats AuthoredStats
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	problemPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              nil,
+			"problems":         bson.M{"$sum": 1},
+			"problem_attempts": bson.M{"$sum": "$attempts"},
+			"correct_answers":  bson.M{"$sum": "$correct_answers"},
+			"problem_upvotes":  bson.M{"$sum": "$upvotes"},
+		}}},
+	}
+	if err := decodeFirst(database.Collection("problems"), problemPipeline, &stats); err != nil {
+		return stats, err
+	}
+
+	listPipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          nil,
+			"lists":        bson.M{"$sum": 1},
+			"list_upvotes": bson.M{"$sum": "$upvotes"},
+			"list_forks":   bson.M{"$sum": "$fork_count"},
+		}}},
+	}
+	if err := decodeFirst(database.Collection("problemlists"), listPipeline, &stats); err != nil {
+		return stats, err
+	}
+
+	if stats.ProblemAttempts > 0 {
+		stats.Accuracy = float64(stats.CorrectAnswers) / float64(stats.ProblemAttempts)
+	}
+
+	return stats, nil
+}
+
+// decodeFirst decodes the first result of the pipeline, leaving the result untouched if there is none.
+func decodeFirst(collection *mongo.Collection, pipeline mongo.Pipeline, result interface{}) error {
+	cursor, err := collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	if cursor.Next(context.Background()) {
+		return cursor.Decode(result)
+	}
+
+	return cursor.Err()
+}
+
+// addCreatedContent records the id in the created content of the user.
+// The field can be one of the following values: "created_problems", "created_lists"
+func (db *MongoDB) addCreatedContent(userID string, field string, id string) error {
+	return db.updateCreatedContent(userID, bson.M{"$addToSet": bson.M{field: id}})
+}
+
+// removeCreatedContent removes the id from the created content of the user.
+func (db *MongoDB) removeCreatedContent(userID string, field string, id string) error {
+	return db.updateCreatedContent(userID, bson.M{"$pull": bson.M{field: id}})
+}
+
+// updateCreatedContent applies the update to the user. Content whose creator is not a
+// user, such as content created before users existed, has nothing to keep in sync.
+func (db *MongoDB) updateCreatedContent(userID string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+
+	return err
+}
+
+// RebuildCreatedContent recomputes the created problems and lists of every user from the
+// creator of each problem and list, fixing the arrays of users created before they were kept in sync.
+func (db *MongoDB) RebuildCreatedContent() error {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	cursor, err := collection.Find(context.Background(), bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var user User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+
+		if err := db.rebuildUserCreatedContent(user.ID); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (db *MongoDB) rebuildUserCreatedContent(userID string) error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	problemIDs, err := createdIDs(database.Collection("problems"), userID)
+	if err != nil {
+		return err
+	}
+
+	listIDs, err := createdIDs(database.Collection("problemlists"), userID)
+	if err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"created_problems": problemIDs, "created_lists": listIDs}}
+	_, err = database.Collection("users").UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+
+	return err
+}
+
+// createdIDs returns the ids of the documents of the collection created by the user, oldest first.
+func createdIDs(collection *mongo.Collection, userID string) ([]string, error) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetProjection(bson.M{"_id": 1})
+	cursor, err := collection.Find(context.Background(), bson.M{"creator_id": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	ids := make([]string, 0)
+	for cursor.Next(context.Background()) {
+		var document struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&document); err != nil {
+			return nil, err
+		}
+		ids = append(ids, document.ID.Hex())
+	}
+
+	return ids, cursor.Err()
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CollaboratorDatabase interface {
+	GetListRole(listID string, userID string) (ListRole, error)
+	InviteListCollaborator(arg InviteListCollaboratorParams, listID string) (ListCollaborator, error)
+	AcceptListInvitation(listID string, userID string) error
+	RemoveListCollaborator(listID string, collaboratorID string, actingUserID string) error
+	ListSharedProblemLists(arg ListSharedProblemListsParams) ([]ProblemList, error)
+}
+
+// GetListRole returns the role of the user on the list, which is empty if the user has no access.
+// Pending invitations grant no role.
+func (db *MongoDB) GetListRole(listID string, userID string) (ListRole, error) {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return NoListRole, errors.New("problem list not found")
+	}
+
+	var problemList ProblemList
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	findOptions := options.FindOne().SetProjection(bson.M{"creator_id": 1, "collaborators": 1})
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}, findOptions).Decode(&problemList)
+	if err == mongo.ErrNoDocuments {
+		return NoListRole, errors.New("problem list not found")
+	}
+	if err != nil {
+		return NoListRole, err
+	}
+
+	return listRole(problemList, userID), nil
+}
+
+func listRole(problemList ProblemList, userID string) ListRole {
+	if userID == "" {
+		return NoListRole
+	}
+	if problemList.CreatorID == userID {
+		return OwnerRole
+	}
+
+	for _, collaborator := range problemList.Collaborators {
+		if collaborator.UserID == userID && collaborator.Status == CollaboratorAccepted {
+			return collaborator.Role
+		}
+	}
+
+	return NoListRole
+}
+
+type InviteListCollaboratorParams struct {
+	CollaboratorID string   `json:"collaborator_id" binding:"required"`
+	Role           ListRole `json:"role" binding:"required,collaborator_role"`
+}
+
+// InviteListCollaborator invites the user to the list with the given role, which applies once accepted.
+func (db *MongoDB) InviteListCollaborator(arg InviteListCollaboratorParams, listID string) (ListCollaborator, error) {
+	collaborator := ListCollaborator{
+		UserID:    arg.CollaboratorID,
+		Role:      arg.Role,
+		Status:    CollaboratorInvited,
+		InvitedAt: time.Now(),
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return collaborator, errors.New("problem list not found")
+	}
+
+	_, err = db.GetUser(arg.CollaboratorID)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return collaborator, errors.New("user not found")
+	}
+	if err != nil {
+		return collaborator, err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	filter := bson.M{
+		"_id":                   objectID,
+		"creator_id":            bson.M{"$ne": arg.CollaboratorID},
+		"collaborators.user_id": bson.M{"$ne": arg.CollaboratorID},
+	}
+	update := bson.M{"$push": bson.M{"collaborators": collaborator}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return collaborator, err
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := collection.CountDocuments(context.Background(), bson.M{"_id": objectID})
+		if err != nil {
+			return collaborator, err
+		}
+		if count == 0 {
+			return collaborator, errors.New("problem list not found")
+		}
+		return collaborator, errors.New("user already collaborates on the list")
+	}
+
+	return collaborator, nil
+}
+
+func (db *MongoDB) AcceptListInvitation(listID string, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return errors.New("invitation not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	filter := bson.M{
+		"_id": objectID,
+		"collaborators": bson.M{"$elemMatch": bson.M{
+			"user_id": userID,
+			"status":  CollaboratorInvited,
+		}},
+	}
+	update := bson.M{"$set": bson.M{
+		"collaborators.$.status":      CollaboratorAccepted,
+		"collaborators.$.accepted_at": time.Now(),
+	}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("invitation not found")
+	}
+
+	return nil
+}
+
+// RemoveListCollaborator removes the collaborator or declines their invitation.
+// Only the owner of the list or the collaborator themselves can do it.
+func (db *MongoDB) RemoveListCollaborator(listID string, collaboratorID string, actingUserID string) error {
+	if actingUserID != collaboratorID {
+		role, err := db.GetListRole(listID, actingUserID)
+		if err != nil {
+			return err
+		}
+		if !role.Includes(OwnerRole) {
+			return errors.New("permission denied")
+		}
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return errors.New("problem list not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	filter := bson.M{"_id": objectID, "collaborators.user_id": collaboratorID}
+	update := bson.M{"$pull": bson.M{"collaborators": bson.M{"user_id": collaboratorID}}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("collaborator not found")
+	}
+
+	return nil
+}
+
+type ListSharedProblemListsParams struct {
+	PaginationParams
+	UserID string
+	Status CollaboratorStatus
+}
+
+// ListSharedProblemLists returns the lists the user collaborates on, or was invited to when the status is invited.
+func (db *MongoDB) ListSharedProblemLists(arg ListSharedProblemListsParams) ([]ProblemList, error) {
+	if arg.Status == "" {
+		arg.Status = CollaboratorAccepted
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	filter := bson.M{"collaborators": bson.M{"$elemMatch": bson.M{
+		"user_id": arg.UserID,
+		"status":  arg.Status,
+	}}}
+	findOptions := options.Find()
+	findOptions.SetLimit(int64(arg.Limit))
+	findOptions.SetSkip(int64(arg.Skip))
+	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	problemLists := make([]ProblemList, 0)
+	for cursor.Next(context.Background()) {
+		var list ProblemList
+		if err := cursor.Decode(&list); err != nil {
+			return nil, err
+		}
+		if err := db.resolveDynamicList(&list); err != nil {
+			return nil, err
+		}
+		problemLists = append(problemLists, list)
+	}
+
+	return problemLists, cursor.Err()
+}
@@ -0,0 +1,36 @@
+package db
+
+import "testing"
+
+func TestListRole(t *testing.T) {
+	problemList := ProblemList{
+		CreatorID: "owner",
+		Collaborators: []ListCollaborator{
+			{UserID: "editor", Role: EditorRole, Status: CollaboratorAccepted},
+			{UserID: "viewer", Role: ViewerRole, Status: CollaboratorAccepted},
+			{UserID: "invited", Role: EditorRole, Status: CollaboratorInvited},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		expected ListRole
+	}{
+		{"creator", "owner", OwnerRole},
+		{"accepted editor", "editor", EditorRole},
+		{"accepted viewer", "viewer", ViewerRole},
+		{"pending invitation", "invited", NoListRole},
+		{"stranger", "stranger", NoListRole},
+		{"anonymous", "", NoListRole},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			role := listRole(problemList, test.userID)
+			if role != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, role)
+			}
+		})
+	}
+}
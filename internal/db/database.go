@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,7 +13,8 @@ import (
 )
 
 type MongoDB struct {
-	client *mongo.Client
+	client           *mongo.Client
+	achievementRules map[AchievementEvent][]AchievementRule
 }
 
 type Database interface {
@@ -22,6 +24,18 @@ type Database interface {
 	LabelsDatabase
 	ProblemEditSuggestionDatabase
 	ProblemAttemptDatabase
+	ReviewDatabase
+	MistakeNotebookDatabase
+	LeaderboardDatabase
+	StreakDatabase
+	AchievementDatabase
+	PracticeDatabase
+	ListSessionDatabase
+	ExamDatabase
+	CollaboratorDatabase
+	SolveLaterDatabase
+	AuthoredContentDatabase
+	IntegrityDatabase
 }
 
 func NewMongoDB() (*MongoDB, error) {
@@ -41,5 +55,44 @@ func NewMongoDB() (*MongoDB, error) {
 	}
 
 	mongoDB := &MongoDB{client: client}
+	if err := mongoDB.loadAchievementRules(); err != nil {
+		log.Println("WARNING: cannot load achievement rules, no badges will be awarded:", err)
+	}
+	if err := mongoDB.ensureIndexes(); err != nil {
+		log.Println("WARNING:", err.Error()+", starting anyway")
+	}
+
 	return mongoDB, err
 }
+
+// ensureIndexes creates the indexes the database relies on, such as the ones enforcing uniqueness.
+// Creating an index that already exists does nothing, while creating a unique index fails
+// if the existing documents are duplicated. Every index is attempted and each failure logged,
+// so one duplicated collection does not keep the other indexes from being created, and the
+// database is used without the failed indexes until they are created by a later start.
+func (db *MongoDB) ensureIndexes() error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	failed := 0
+	for _, indexes := range []map[string]mongo.IndexModel{labelIndexes, examIndexes, listReportIndexes, leaderboardIndexes, historyIndexes, attemptStartIndexes} {
+		for name, index := range indexes {
+			_, err := database.Collection(name).Indexes().CreateOne(context.Background(), index)
+			if err != nil {
+				log.Println("ERROR: cannot create index on", name+":", err)
+				if index.Options != nil && index.Options.Unique != nil && *index.Options.Unique {
+					log.Println("ERROR: uniqueness of", name, "is NOT enforced until the index is created")
+				}
+				if _, ok := labelIndexes[name]; ok {
+					log.Println("ERROR: duplicated labels can be merged with make dedupe-labels")
+				}
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return errors.New("cannot create " + strconv.Itoa(failed) + " indexes")
+	}
+
+	return nil
+}
@@ -0,0 +1,558 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExamDatabase runs problem lists as timed exams.
+// Exam answers are graded against the answer keys stored with the problems and are kept
+// apart from the problem attempts, so they never count for problem stats or leaderboards.
+type ExamDatabase interface {
+	SetExamSettings(arg SetExamSettingsParams, listID string) error
+	RemoveExamSettings(listID string) error
+	StartExamAttempt(arg StartExamAttemptParams, listID string) (ExamView, error)
+	GetExamAttempt(id string, userID string) (ExamView, error)
+	SaveExamAnswer(arg SaveExamAnswerParams, attemptID string) (SavedExamAnswer, error)
+	SubmitExamAttempt(arg SubmitExamAttemptParams, id string) (ExamReport, error)
+	GetExamReport(id string, userID string) (ExamReport, error)
+
+	HideExamAnswers(userID string, problems []AnyProblem) ([]interface{}, error)
+}
+
+type SetExamSettingsParams struct {
+	DurationMinutes int           `json:"duration_minutes" binding:"required,min=1,max=600"`
+	AllowedAttempts int           `json:"allowed_attempts" binding:"required,min=1,max=100"`
+	RevealAnswers   RevealAnswers `json:"reveal_answers" binding:"required,reveal_answers"`
+}
+
+func (db *MongoDB) SetExamSettings(arg SetExamSettingsParams, listID string) error {
+	settings := ExamSettings{
+		DurationMinutes: arg.DurationMinutes,
+		AllowedAttempts: arg.AllowedAttempts,
+		RevealAnswers:   arg.RevealAnswers,
+	}
+
+	return db.updateExamSettings(listID, bson.M{"$set": bson.M{"exam": settings}})
+}
+
+// RemoveExamSettings turns the exam back into a regular list. Attempts already started are not affected.
+func (db *MongoDB) RemoveExamSettings(listID string) error {
+	return db.updateExamSettings(listID, bson.M{"$unset": bson.M{"exam": ""}})
+}
+
+func (db *MongoDB) updateExamSettings(listID string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return errors.New("problem list not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("problem list not found")
+	}
+
+	return nil
+}
+
+type StartExamAttemptParams struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// ExamView is an exam attempt with its problems, which never include the answer keys.
+type ExamView struct {
+	Attempt  ExamAttempt       `json:"attempt"`
+	Problems []StrippedProblem `json:"problems"`
+}
+
+// StartExamAttempt starts a new attempt at the exam, or returns the attempt the user has in progress.
+func (db *MongoDB) StartExamAttempt(arg StartExamAttemptParams, listID string) (ExamView, error) {
+	var attempt ExamAttempt
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("exam_attempts")
+	filter := bson.M{"list_id": listID, "user_id": arg.UserID, "status": ExamInProgress}
+	err := collection.FindOne(context.Background(), filter).Decode(&attempt)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return ExamView{}, err
+	}
+
+	if err == nil {
+		attempt, err = db.expireExamAttempt(attempt)
+		if err != nil {
+			return ExamView{}, err
+		}
+		if attempt.Status == ExamInProgress {
+			return db.examView(attempt)
+		}
+	}
+
+	problemList, err := db.GetVisibleProblemList(listID, arg.UserID)
+	if err != nil {
+		return ExamView{}, err
+	}
+
+	if problemList.Exam == nil {
+		return ExamView{}, errors.New("problem list is not an exam")
+	}
+
+	if err := db.takeExamAttempt(listID, arg.UserID, problemList.Exam.AllowedAttempts); err != nil {
+		return ExamView{}, err
+	}
+
+	problemIDs, err := db.visibleProblemIDs(problemList.ProblemIDs, arg.UserID)
+	if err != nil {
+		return ExamView{}, err
+	}
+
+	startedAt := time.Now()
+	attempt = ExamAttempt{
+		ListID:     listID,
+		UserID:     arg.UserID,
+		Settings:   *problemList.Exam,
+		ProblemIDs: problemIDs,
+		Answers:    map[string]ExamAnswer{},
+		Status:     ExamInProgress,
+		StartedAt:  startedAt,
+		Deadline:   startedAt.Add(time.Duration(problemList.Exam.DurationMinutes) * time.Minute),
+		MaxScore:   len(problemIDs),
+	}
+
+	result, err := collection.InsertOne(context.Background(), attempt)
+	if err != nil {
+		return ExamView{}, err
+	}
+	attempt.ID = result.InsertedID.(primitive.ObjectID).Hex()
+
+	return db.examView(attempt)
+}
+
+// examIndexes keep a single counter of started attempts per user and exam.
+var examIndexes = map[string]mongo.IndexModel{
+	"exam_attempt_counts": {
+		Keys:    bson.D{{Key: "list_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+}
+
+// takeExamAttempt uses one of the allowed attempts of the user at the exam. The counter is only
+// incremented while it is under the limit, so concurrent starts cannot exceed it. Users who
+// started attempts before the counter existed get a counter holding their existing attempts.
+func (db *MongoDB) takeExamAttempt(listID string, userID string, allowedAttempts int) error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+	counters := database.Collection("exam_attempt_counts")
+	filter := bson.M{"list_id": listID, "user_id": userID}
+
+	err := counters.FindOne(context.Background(), filter).Err()
+	if err == mongo.ErrNoDocuments {
+		started, err := database.Collection("exam_attempts").CountDocuments(context.Background(), filter)
+		if err != nil {
+			return err
+		}
+
+		counter := bson.M{"list_id": listID, "user_id": userID, "started": started}
+		_, err = counters.InsertOne(context.Background(), counter)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	filter["started"] = bson.M{"$lt": allowedAttempts}
+	result, err := counters.UpdateOne(context.Background(), filter, bson.M{"$inc": bson.M{"started": 1}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("no exam attempts left")
+	}
+
+	return nil
+}
+
+// getExamAttempt returns the attempt of the user, submitting it first if its deadline has passed.
+// An attempt of another user is reported as not found.
+func (db *MongoDB) getExamAttempt(id string, userID string) (ExamAttempt, error) {
+	var attempt ExamAttempt
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return attempt, errors.New("exam attempt not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("exam_attempts")
+	filter := bson.M{"_id": objectID, "user_id": userID}
+	err = collection.FindOne(context.Background(), filter).Decode(&attempt)
+	if err == mongo.ErrNoDocuments {
+		return attempt, errors.New("exam attempt not found")
+	}
+	if err != nil {
+		return attempt, err
+	}
+
+	return db.expireExamAttempt(attempt)
+}
+
+func (db *MongoDB) expireExamAttempt(attempt ExamAttempt) (ExamAttempt, error) {
+	if attempt.Status != ExamInProgress || time.Now().Before(attempt.Deadline) {
+		return attempt, nil
+	}
+
+	return db.finishExamAttempt(attempt, ExamExpired, attempt.Deadline)
+}
+
+func (db *MongoDB) GetExamAttempt(id string, userID string) (ExamView, error) {
+	attempt, err := db.getExamAttempt(id, userID)
+	if err != nil {
+		return ExamView{}, err
+	}
+
+	return db.examView(attempt)
+}
+
+func (db *MongoDB) examView(attempt ExamAttempt) (ExamView, error) {
+	view := ExamView{Attempt: attempt, Problems: []StrippedProblem{}}
+
+	problems, err := db.examProblems(attempt)
+	if err != nil {
+		return view, err
+	}
+
+	for _, problemID := range attempt.ProblemIDs {
+		if problem, ok := problems[problemID]; ok {
+			view.Problems = append(view.Problems, strippedProblem(problem))
+		}
+	}
+
+	return view, nil
+}
+
+// HideExamAnswers prepares problems to be served to the user outside of an exam. Problems that are
+// part of an exam the user is taking are stripped of their answer key, so the answers of a running
+// exam cannot be read through the other endpoints. Everyone else sees the problems as they are.
+func (db *MongoDB) HideExamAnswers(userID string, problems []AnyProblem) ([]interface{}, error) {
+	inExam, err := db.runningExamProblemIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]interface{}, 0, len(problems))
+	for _, problem := range problems {
+		if inExam[problem.ID] {
+			views = append(views, strippedProblem(problem))
+			continue
+		}
+		views = append(views, problem)
+	}
+
+	return views, nil
+}
+
+// hideExamAnswer prepares a single problem to be served to the user, like HideExamAnswers.
+func (db *MongoDB) hideExamAnswer(userID string, problem AnyProblem) (interface{}, error) {
+	views, err := db.HideExamAnswers(userID, []AnyProblem{problem})
+	if err != nil {
+		return nil, err
+	}
+	return views[0], nil
+}
+
+// runningExamProblemIDs returns the problems of the exams the user is taking.
+func (db *MongoDB) runningExamProblemIDs(userID string) (map[string]bool, error) {
+	inExam := make(map[string]bool)
+	if userID == "" {
+		return inExam, nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("exam_attempts")
+	filter := bson.M{
+		"user_id":  userID,
+		"status":   ExamInProgress,
+		"deadline": bson.M{"$gt": time.Now()},
+	}
+	problemIDs, err := collection.Distinct(context.Background(), "problem_ids", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, problemID := range problemIDs {
+		if id, ok := problemID.(string); ok {
+			inExam[id] = true
+		}
+	}
+
+	return inExam, nil
+}
+
+// checkNotInRunningExam refuses regular attempts at a problem of an exam the user is taking,
+// since grading them would reveal the answer before the exam is over.
+func (db *MongoDB) checkNotInRunningExam(userID string, problemID string) error {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("exam_attempts")
+	filter := bson.M{
+		"user_id":     userID,
+		"status":      ExamInProgress,
+		"deadline":    bson.M{"$gt": time.Now()},
+		"problem_ids": problemID,
+	}
+	count, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("problem is part of an exam in progress")
+	}
+
+	return nil
+}
+
+func (db *MongoDB) examProblems(attempt ExamAttempt) (map[string]AnyProblem, error) {
+	var problemIDs []primitive.ObjectID
+	for _, problemID := range attempt.ProblemIDs {
+		if objectID, err := primitive.ObjectIDFromHex(problemID); err == nil {
+			problemIDs = append(problemIDs, objectID)
+		}
+	}
+
+	if len(problemIDs) == 0 {
+		return map[string]AnyProblem{}, nil
+	}
+
+	return db.problemsByID(problemIDs, "", attempt.UserID)
+}
+
+type SaveExamAnswerParams struct {
+	UserID        string `json:"user_id" binding:"required"`
+	ProblemID     string `json:"problem_id" binding:"required"`
+	BoolResponse  *bool  `json:"bool_response"`
+	BoolResponses []bool `json:"bool_responses"`
+	ItemResponse  *int   `json:"item_response"`
+	ItemResponses []bool `json:"item_responses"`
+}
+
+// SavedExamAnswer only carries the problem with its answer key when the exam reveals answers immediately.
+type SavedExamAnswer struct {
+	Answer  ExamAnswer  `json:"answer"`
+	Problem *AnyProblem `json:"problem,omitempty"`
+}
+
+// SaveExamAnswer autosaves the response of the user while the exam is in progress.
+// Responses can be changed until the deadline, unless the answer was already revealed.
+func (db *MongoDB) SaveExamAnswer(arg SaveExamAnswerParams, attemptID string) (SavedExamAnswer, error) {
+	var saved SavedExamAnswer
+
+	attempt, err := db.getExamAttempt(attemptID, arg.UserID)
+	if err != nil {
+		return saved, err
+	}
+
+	if attempt.Status != ExamInProgress {
+		return saved, errors.New("exam attempt is over")
+	}
+
+	if !containsString(attempt.ProblemIDs, arg.ProblemID) {
+		return saved, errors.New("problem is not part of the exam")
+	}
+
+	problem, err := db.GetProblem(arg.ProblemID)
+	if err != nil {
+		return saved, err
+	}
+
+	saved.Answer = ExamAnswer{
+		ProblemResponse: ProblemResponse{
+			BoolResponse:  arg.BoolResponse,
+			BoolResponses: arg.BoolResponses,
+			ItemResponse:  arg.ItemResponse,
+			ItemResponses: arg.ItemResponses,
+		},
+		SavedAt: time.Now(),
+	}
+	if !responseMatches(problem, saved.Answer.ProblemResponse) {
+		return saved, errors.New("answer does not match the problem")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(attempt.ID)
+	if err != nil {
+		return saved, err
+	}
+
+	answerField := "answers." + arg.ProblemID
+	filter := bson.M{
+		"_id":      objectID,
+		"status":   ExamInProgress,
+		"deadline": bson.M{"$gt": saved.Answer.SavedAt},
+	}
+
+	reveal := attempt.Settings.RevealAnswers == RevealImmediately
+	if reveal {
+		if _, answered := attempt.Answers[arg.ProblemID]; answered {
+			return saved, errors.New("answer already revealed")
+		}
+
+		solutionAccuracy := gradeResponse(problem, saved.Answer.ProblemResponse)
+		saved.Answer.SolutionAccuracy = &solutionAccuracy
+		saved.Problem = &problem
+		filter[answerField] = bson.M{"$exists": false}
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("exam_attempts")
+	update := bson.M{"$set": bson.M{answerField: saved.Answer}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return saved, err
+	}
+
+	if result.MatchedCount == 0 {
+		return SavedExamAnswer{}, errors.New("exam attempt is over")
+	}
+
+	return saved, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// finishExamAttempt grades every answer and closes the attempt.
+// Problems deleted since the attempt started do not count for the score.
+func (db *MongoDB) finishExamAttempt(attempt ExamAttempt, status ExamAttemptStatus, finishedAt time.Time) (ExamAttempt, error) {
+	problems, err := db.examProblems(attempt)
+	if err != nil {
+		return attempt, err
+	}
+
+	score := 0.0
+	for problemID, answer := range attempt.Answers {
+		problem, ok := problems[problemID]
+		if !ok {
+			continue
+		}
+
+		solutionAccuracy := gradeResponse(problem, answer.ProblemResponse)
+		answer.SolutionAccuracy = &solutionAccuracy
+		attempt.Answers[problemID] = answer
+		score += attemptScore(solutionAccuracy)
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(attempt.ID)
+	if err != nil {
+		return attempt, err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("exam_attempts")
+	filter := bson.M{"_id": objectID, "status": ExamInProgress}
+	update := bson.M{"$set": bson.M{
+		"answers":      attempt.Answers,
+		"status":       status,
+		"submitted_at": finishedAt,
+		"score":        score,
+		"max_score":    len(problems),
+	}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return attempt, err
+	}
+
+	if result.MatchedCount == 0 {
+		// the attempt was finished by a concurrent request
+		err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&attempt)
+		return attempt, err
+	}
+
+	attempt.Status = status
+	attempt.SubmittedAt = finishedAt
+	attempt.Score = score
+	attempt.MaxScore = len(problems)
+
+	return attempt, nil
+}
+
+type ExamReport struct {
+	Attempt ExamAttempt  `json:"attempt"`
+	Results []ExamResult `json:"results"`
+}
+
+// ExamResult is a problem of the exam with its answer key. Answer is nil for unanswered problems.
+type ExamResult struct {
+	Problem          AnyProblem       `json:"problem"`
+	Answer           *ExamAnswer      `json:"answer"`
+	SolutionAccuracy SolutionAccuracy `json:"solution_accuracy"`
+}
+
+type SubmitExamAttemptParams struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+func (db *MongoDB) SubmitExamAttempt(arg SubmitExamAttemptParams, id string) (ExamReport, error) {
+	attempt, err := db.getExamAttempt(id, arg.UserID)
+	if err != nil {
+		return ExamReport{}, err
+	}
+
+	if attempt.Status == ExamInProgress {
+		attempt, err = db.finishExamAttempt(attempt, ExamSubmitted, time.Now())
+		if err != nil {
+			return ExamReport{}, err
+		}
+	}
+
+	return db.examReport(attempt)
+}
+
+// GetExamReport returns the graded report, which is only available once the attempt is over.
+func (db *MongoDB) GetExamReport(id string, userID string) (ExamReport, error) {
+	attempt, err := db.getExamAttempt(id, userID)
+	if err != nil {
+		return ExamReport{}, err
+	}
+
+	if attempt.Status == ExamInProgress {
+		return ExamReport{}, errors.New("exam attempt still in progress")
+	}
+
+	return db.examReport(attempt)
+}
+
+func (db *MongoDB) examReport(attempt ExamAttempt) (ExamReport, error) {
+	report := ExamReport{Attempt: attempt, Results: []ExamResult{}}
+
+	problems, err := db.examProblems(attempt)
+	if err != nil {
+		return report, err
+	}
+
+	for _, problemID := range attempt.ProblemIDs {
+		problem, ok := problems[problemID]
+		if !ok {
+			continue
+		}
+
+		result := ExamResult{Problem: problem, SolutionAccuracy: Incorrect}
+		if answer, answered := attempt.Answers[problemID]; answered {
+			result.Answer = &answer
+			if answer.SolutionAccuracy != nil {
+				result.SolutionAccuracy = *answer.SolutionAccuracy
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,418 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IntegrityDatabase interface {
+	CheckConsistency(fix bool) (ConsistencyReport, error)
+}
+
+// checkLabels verifies that the labels exist and form a path, where a topic belongs to the
+// subject and a subtopic to the topic. Every label is optional, but a topic needs a subject
+// and a subtopic needs a topic.
+func (db *MongoDB) checkLabels(subjectID string, topicID string, subtopicID string) error {
+	if subjectID != "" {
+		if _, err := db.GetSubject(subjectID); err != nil {
+			return labelLookupError(err, "subject not found")
+		}
+	}
+
+	if topicID != "" {
+		topic, err := db.GetTopic(topicID)
+		if err != nil {
+			return labelLookupError(err, "topic not found")
+		}
+		if topic.SubjectID != subjectID {
+			return errors.New("topic does not belong to subject")
+		}
+	}
+
+	if subtopicID != "" {
+		subtopic, err := db.GetSubtopic(subtopicID)
+		if err != nil {
+			return labelLookupError(err, "subtopic not found")
+		}
+		if subtopic.TopicID != topicID {
+			return errors.New("subtopic does not belong to topic")
+		}
+	}
+
+	return nil
+}
+
+func labelLookupError(err error, notFound string) error {
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return errors.New(notFound)
+	}
+	return err
+}
+
+// checkProblemIDs verifies that every problem exists.
+func (db *MongoDB) checkProblemIDs(problemIDs []string) error {
+	objectIDs := make([]primitive.ObjectID, 0, len(problemIDs))
+	for _, problemID := range problemIDs {
+		objectID, err := primitive.ObjectIDFromHex(problemID)
+		if err != nil {
+			return errors.New("list references unknown problems")
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
+	existing, err := collection.Distinct(context.Background(), "_id", bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return err
+	}
+
+	unique := make(map[primitive.ObjectID]bool)
+	for _, objectID := range objectIDs {
+		unique[objectID] = true
+	}
+	if len(existing) != len(unique) {
+		return errors.New("list references unknown problems")
+	}
+
+	return nil
+}
+
+// deleteProblemReferences removes what only makes sense while the problem exists: its place in
+// lists and bookmarks, edit suggestions, reports, pending attempt starts and review histories.
+// Attempts are kept, so streaks, leaderboards and achievements of the users do not change.
+func (db *MongoDB) deleteProblemReferences(problemID string) error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	pull := bson.M{"$pull": bson.M{"problem_ids": problemID}}
+	_, err := database.Collection("problemlists").UpdateMany(context.Background(), bson.M{"problem_ids": problemID}, pull)
+	if err != nil {
+		return err
+	}
+
+	pull = bson.M{"$pull": bson.M{"solve_later_problems": problemID, "solve_later_done": problemID}}
+	_, err = database.Collection("users").UpdateMany(context.Background(), bson.M{"solve_later_problems": problemID}, pull)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{"problem_edit_suggestions", "problem_reports", "attempt_starts", "user_problem_histories"} {
+		_, err := database.Collection(name).DeleteMany(context.Background(), bson.M{"problem_id": problemID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteListReferences removes the bookmarks, votes and reports of the list.
+// Sessions and exam attempts are kept as the record of the user results.
+func (db *MongoDB) deleteListReferences(listID string) error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	pull := bson.M{"$pull": bson.M{"solve_later_lists": listID}}
+	_, err := database.Collection("users").UpdateMany(context.Background(), bson.M{"solve_later_lists": listID}, pull)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{"user_list_votes", "list_reports"} {
+		_, err := database.Collection(name).DeleteMany(context.Background(), bson.M{"list_id": listID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConsistencyReport counts the references to problems, lists and labels that no longer exist.
+type ConsistencyReport struct {
+	// ListProblems counts the missing problems referenced by lists
+	ListProblems int `json:"list_problems"`
+	// SolveLater counts the missing problems and lists bookmarked by users
+	SolveLater int `json:"solve_later"`
+	// Orphans counts, by collection, the documents of missing problems and lists
+	Orphans map[string]int `json:"orphans"`
+	// Attempts counts the attempts of missing problems, which are kept on purpose
+	Attempts int `json:"attempts"`
+	// ProblemLabels counts the problems whose labels are missing or inconsistent
+	ProblemLabels int `json:"problem_labels"`
+}
+
+// CheckConsistency reports the dangling references of existing data. With fix, the references
+// are removed the same way deleting a problem or list removes them, and invalid labels are cleared.
+func (db *MongoDB) CheckConsistency(fix bool) (ConsistencyReport, error) {
+	report := ConsistencyReport{Orphans: make(map[string]int)}
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	problemIDs, err := existingIDs(database.Collection("problems"))
+	if err != nil {
+		return report, err
+	}
+	listIDs, err := existingIDs(database.Collection("problemlists"))
+	if err != nil {
+		return report, err
+	}
+
+	missingProblems, err := missingReferences(database.Collection("problemlists"), "problem_ids", problemIDs)
+	if err != nil {
+		return report, err
+	}
+	report.ListProblems, err = countReferences(database.Collection("problemlists"), "problem_ids", missingProblems)
+	if err != nil {
+		return report, err
+	}
+
+	for _, field := range []string{"solve_later_problems", "solve_later_lists"} {
+		existing := problemIDs
+		if field == "solve_later_lists" {
+			existing = listIDs
+		}
+
+		missing, err := missingReferences(database.Collection("users"), field, existing)
+		if err != nil {
+			return report, err
+		}
+		count, err := countReferences(database.Collection("users"), field, missing)
+		if err != nil {
+			return report, err
+		}
+		report.SolveLater += count
+	}
+
+	orphanCollections := []struct {
+		name     string
+		field    string
+		existing map[string]bool
+	}{
+		{"problem_edit_suggestions", "problem_id", problemIDs},
+		{"problem_reports", "problem_id", problemIDs},
+		{"attempt_starts", "problem_id", problemIDs},
+		{"user_problem_histories", "problem_id", problemIDs},
+		{"user_list_votes", "list_id", listIDs},
+		{"list_reports", "list_id", listIDs},
+	}
+	for _, orphans := range orphanCollections {
+		missing, err := missingReferences(database.Collection(orphans.name), orphans.field, orphans.existing)
+		if err != nil {
+			return report, err
+		}
+		count, err := database.Collection(orphans.name).CountDocuments(context.Background(), bson.M{orphans.field: bson.M{"$in": missing}})
+		if err != nil {
+			return report, err
+		}
+		report.Orphans[orphans.name] = int(count)
+	}
+
+	missingAttempted, err := missingReferences(database.Collection("problem_attempts"), "problem_id", problemIDs)
+	if err != nil {
+		return report, err
+	}
+	attempts, err := database.Collection("problem_attempts").CountDocuments(context.Background(), bson.M{"problem_id": bson.M{"$in": missingAttempted}})
+	if err != nil {
+		return report, err
+	}
+	report.Attempts = int(attempts)
+
+	report.ProblemLabels, err = db.checkProblemLabels(fix)
+	if err != nil {
+		return report, err
+	}
+
+	if !fix {
+		return report, nil
+	}
+
+	for _, problemID := range missingProblems {
+		if err := db.deleteProblemReferences(problemID); err != nil {
+			return report, err
+		}
+	}
+	for _, orphans := range orphanCollections {
+		missing, err := missingReferences(database.Collection(orphans.name), orphans.field, orphans.existing)
+		if err != nil {
+			return report, err
+		}
+		_, err = database.Collection(orphans.name).DeleteMany(context.Background(), bson.M{orphans.field: bson.M{"$in": missing}})
+		if err != nil {
+			return report, err
+		}
+	}
+	missingLists, err := missingReferences(database.Collection("users"), "solve_later_lists", listIDs)
+	if err != nil {
+		return report, err
+	}
+	for _, listID := range missingLists {
+		if err := db.deleteListReferences(listID); err != nil {
+			return report, err
+		}
+	}
+	missingBookmarks, err := missingReferences(database.Collection("users"), "solve_later_problems", problemIDs)
+	if err != nil {
+		return report, err
+	}
+	for _, problemID := range missingBookmarks {
+		if err := db.deleteProblemReferences(problemID); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// checkProblemLabels counts the problems with missing or inconsistent labels. With fix, the labels
+// are cleared from the first invalid one down, so the problem keeps the valid part of its path.
+func (db *MongoDB) checkProblemLabels(fix bool) (int, error) {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	subjects, err := labelParents(database.Collection("subjects"), "")
+	if err != nil {
+		return 0, err
+	}
+	topics, err := labelParents(database.Collection("topics"), "subject_id")
+	if err != nil {
+		return 0, err
+	}
+	subtopics, err := labelParents(database.Collection("subtopics"), "topic_id")
+	if err != nil {
+		return 0, err
+	}
+
+	projection := bson.M{"subject_id": 1, "topic_id": 1, "subtopic_id": 1}
+	cursor, err := database.Collection("problems").Find(context.Background(), bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	invalid := 0
+	for cursor.Next(context.Background()) {
+		var problem Problem
+		if err := cursor.Decode(&problem); err != nil {
+			return invalid, err
+		}
+
+		fields := bson.M{}
+		if _, ok := subjects[problem.SubjectID]; problem.SubjectID != "" && !ok {
+			fields["subject_id"] = ""
+		}
+		if parent, ok := topics[problem.TopicID]; problem.TopicID != "" && (!ok || parent != problem.SubjectID || len(fields) > 0) {
+			fields["topic_id"] = ""
+		}
+		if parent, ok := subtopics[problem.SubtopicID]; problem.SubtopicID != "" && (!ok || parent != problem.TopicID || len(fields) > 0) {
+			fields["subtopic_id"] = ""
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		invalid++
+		if !fix {
+			continue
+		}
+
+		objectID, err := primitive.ObjectIDFromHex(problem.ID)
+		if err != nil {
+			return invalid, err
+		}
+		_, err = database.Collection("problems").UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{"$set": fields})
+		if err != nil {
+			return invalid, err
+		}
+	}
+
+	return invalid, cursor.Err()
+}
+
+// existingIDs returns the ids of every document of the collection.
+func existingIDs(collection *mongo.Collection) (map[string]bool, error) {
+	ids, err := collection.Distinct(context.Background(), "_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if objectID, ok := id.(primitive.ObjectID); ok {
+			existing[objectID.Hex()] = true
+		}
+	}
+
+	return existing, nil
+}
+
+// labelParents maps the id of every label of the collection to the id of its parent label.
+func labelParents(collection *mongo.Collection, parentField string) (map[string]string, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	parents := make(map[string]string)
+	for cursor.Next(context.Background()) {
+		var label bson.M
+		if err := cursor.Decode(&label); err != nil {
+			return nil, err
+		}
+
+		objectID, ok := label["_id"].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		parent, _ := label[parentField].(string)
+		parents[objectID.Hex()] = parent
+	}
+
+	return parents, cursor.Err()
+}
+
+// missingReferences returns the distinct values of the field that are not existing ids.
+func missingReferences(collection *mongo.Collection, field string, existing map[string]bool) ([]string, error) {
+	values, err := collection.Distinct(context.Background(), field, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]string, 0)
+	for _, value := range values {
+		id, ok := value.(string)
+		if ok && !existing[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing, nil
+}
+
+// countReferences counts the references to the ids held in the array field across the collection.
+func countReferences(collection *mongo.Collection, field string, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{field: bson.M{"$in": ids}}}},
+		{{Key: "$unwind", Value: "$" + field}},
+		{{Key: "$match", Value: bson.M{field: bson.M{"$in": ids}}}},
+		{{Key: "$count", Value: "references"}},
+	}
+
+	var result struct {
+		References int `bson:"references"`
+	}
+	err := decodeFirst(collection, pipeline, &result)
+
+	return result.References, err
+}
@@ -0,0 +1,462 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RenameLabelParams struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type MergeLabelParams struct {
+	TargetID string `json:"target_id" binding:"required"`
+}
+
+func (level LabelLevel) collection() string {
+	return string(level) + "s"
+}
+
+// field is the name of the field holding a label of this level in problems, attempts and child labels.
+func (level LabelLevel) field() string {
+	return string(level) + "_id"
+}
+
+// child returns the level of the labels under this level, which is empty for subtopics.
+func (level LabelLevel) child() LabelLevel {
+	switch level {
+	case SubjectLevel:
+		return TopicLevel
+	case TopicLevel:
+		return SubtopicLevel
+	}
+	return ""
+}
+
+// labelPath is a label along with the labels above it.
+type labelPath struct {
+	name     string
+	language string
+	fields   bson.M
+}
+
+// getLabelPath returns the label and, for topics and subtopics, the ids of the labels above it,
+// so documents re-tagged with the label stay consistent with its subject and topic.
+func (db *MongoDB) getLabelPath(level LabelLevel, id string) (labelPath, error) {
+	notFound := string(level) + " not found"
+
+	switch level {
+	case SubjectLevel:
+		subject, err := db.GetSubject(id)
+		if err != nil {
+			return labelPath{}, labelLookupError(err, notFound)
+		}
+		return labelPath{name: subject.Name, language: subject.Language, fields: bson.M{"subject_id": subject.ID}}, nil
+	case TopicLevel:
+		topic, err := db.GetTopic(id)
+		if err != nil {
+			return labelPath{}, labelLookupError(err, notFound)
+		}
+		return labelPath{name: topic.Name, fields: bson.M{"subject_id": topic.SubjectID, "topic_id": topic.ID}}, nil
+	case SubtopicLevel:
+		subtopic, err := db.GetSubtopic(id)
+		if err != nil {
+			return labelPath{}, labelLookupError(err, notFound)
+		}
+		topic, err := db.GetTopic(subtopic.TopicID)
+		if err != nil {
+			return labelPath{}, labelLookupError(err, "topic not found")
+		}
+		fields := bson.M{"subject_id": topic.SubjectID, "topic_id": subtopic.TopicID, "subtopic_id": subtopic.ID}
+		return labelPath{name: subtopic.Name, fields: fields}, nil
+	}
+
+	return labelPath{}, errors.New(notFound)
+}
+
+// RenameLabel renames a subject, topic or subtopic, which must stay unique within its parent.
+func (db *MongoDB) RenameLabel(level LabelLevel, id string, arg RenameLabelParams) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New(string(level) + " not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection(level.collection())
+	update := bson.M{"$set": bson.M{"name": strings.TrimSpace(arg.Name)}}
+	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.New(string(level) + " already exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New(string(level) + " not found")
+	}
+
+	return nil
+}
+
+// DeleteLabel deletes a label that is no longer used by problems, edit suggestions, lists
+// or child labels. With a reassignment target, the label is merged into the target instead.
+func (db *MongoDB) DeleteLabel(level LabelLevel, id string, reassignTo string) error {
+	if reassignTo != "" {
+		_, err := db.MergeLabels(level, id, MergeLabelParams{TargetID: reassignTo})
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New(string(level) + " not found")
+	}
+
+	inUse, err := db.labelInUse(level, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New(string(level) + " is in use")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection(level.collection())
+	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New(string(level) + " not found")
+	}
+
+	return nil
+}
+
+func (db *MongoDB) labelInUse(level LabelLevel, id string) (bool, error) {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	uses := map[string]bson.M{
+		"problems":                 {level.field(): id},
+		"problem_edit_suggestions": {level.field(): id},
+		"problemlists":             {"$or": bson.A{bson.M{level.field(): id}, bson.M{"query." + level.field(): id}}},
+	}
+	if child := level.child(); child != "" {
+		uses[child.collection()] = bson.M{level.field(): id}
+	}
+
+	for name, filter := range uses {
+		count, err := database.Collection(name).CountDocuments(context.Background(), filter)
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// MergeLabels merges the source label into the target label of the same level. Problems, edit
+// suggestions, attempts, review histories, lists and child labels are re-tagged with the target,
+// children named like a child of the target are merged into it, leaderboard entries are added to
+// the ones of the target, the source is deleted and the merge is recorded, all in one transaction,
+// which requires the database to run as a replica set. Subjects must be of the same language.
+func (db *MongoDB) MergeLabels(level LabelLevel, sourceID string, arg MergeLabelParams) (LabelMerge, error) {
+	if sourceID == arg.TargetID {
+		return LabelMerge{}, errors.New("cannot merge a label into itself")
+	}
+
+	source, err := db.getLabelPath(level, sourceID)
+	if err != nil {
+		return LabelMerge{}, err
+	}
+
+	target, err := db.getLabelPath(level, arg.TargetID)
+	if err != nil {
+		if err.Error() == string(level)+" not found" {
+			return LabelMerge{}, errors.New("merge target not found")
+		}
+		return LabelMerge{}, err
+	}
+
+	if source.language != target.language {
+		return LabelMerge{}, errors.New("cannot merge subjects of different languages")
+	}
+
+	merge := LabelMerge{
+		Level:      level,
+		SourceID:   sourceID,
+		SourceName: source.name,
+		TargetID:   arg.TargetID,
+		TargetName: target.name,
+		MergedAt:   time.Now(),
+	}
+
+	session, err := db.client.StartSession()
+	if err != nil {
+		return merge, err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		return nil, db.mergeLabels(sessionContext, level, target, &merge)
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return merge, errors.New("merge would duplicate child labels")
+	}
+
+	return merge, err
+}
+
+func (db *MongoDB) mergeLabels(ctx mongo.SessionContext, level LabelLevel, target labelPath, merge *LabelMerge) error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+	source := bson.M{level.field(): merge.SourceID}
+
+	// the histories only hold the subject, so they follow the problems being re-tagged
+	retagged, err := database.Collection("problems").Distinct(ctx, "_id", source)
+	if err != nil {
+		return err
+	}
+	problemIDs := make([]string, 0, len(retagged))
+	for _, id := range retagged {
+		if objectID, ok := id.(primitive.ObjectID); ok {
+			problemIDs = append(problemIDs, objectID.Hex())
+		}
+	}
+	merge.Problems = len(problemIDs)
+
+	for _, name := range []string{"problems", "problem_edit_suggestions", "problem_attempts"} {
+		_, err := database.Collection(name).UpdateMany(ctx, source, bson.M{"$set": target.fields})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(problemIDs) > 0 {
+		filter := bson.M{"problem_id": bson.M{"$in": problemIDs}}
+		update := bson.M{"$set": bson.M{"subject_id": target.fields["subject_id"]}}
+		_, err = database.Collection("user_problem_histories").UpdateMany(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+	}
+
+	if level == SubjectLevel {
+		_, err = database.Collection("problemlists").UpdateMany(ctx, source, bson.M{"$set": target.fields})
+		if err != nil {
+			return err
+		}
+	}
+
+	queryFields := bson.M{}
+	for field, id := range target.fields {
+		queryFields["query."+field] = id
+	}
+	querySource := bson.M{"query." + level.field(): merge.SourceID}
+	_, err = database.Collection("problemlists").UpdateMany(ctx, querySource, bson.M{"$set": queryFields})
+	if err != nil {
+		return err
+	}
+
+	if level.child() != "" {
+		if err := db.mergeChildLabels(ctx, level, target, merge); err != nil {
+			return err
+		}
+	}
+
+	if err := db.foldLeaderboardEntries(ctx, level, merge); err != nil {
+		return err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(merge.SourceID)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection(level.collection()).DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	result, err := database.Collection("label_merges").InsertOne(ctx, merge)
+	if err != nil {
+		return err
+	}
+	merge.ID = result.InsertedID.(primitive.ObjectID).Hex()
+
+	return nil
+}
+
+// mergeChildLabels moves the children of the source under the target. A child named like a child
+// of the target, by the same collation as the unique label indexes, is merged into it instead.
+func (db *MongoDB) mergeChildLabels(ctx mongo.SessionContext, level LabelLevel, target labelPath, merge *LabelMerge) error {
+	child := level.child()
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection(child.collection())
+
+	type childLabel struct {
+		ID   string `bson:"_id"`
+		Name string `bson:"name"`
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{level.field(): merge.SourceID})
+	if err != nil {
+		return err
+	}
+	var children []childLabel
+	if err := cursor.All(ctx, &children); err != nil {
+		return err
+	}
+
+	findOptions := options.FindOne().SetCollation(labelNameCollation)
+	for _, source := range children {
+		var twin childLabel
+		filter := bson.M{level.field(): merge.TargetID, "name": source.Name}
+		err := collection.FindOne(ctx, filter, findOptions).Decode(&twin)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		twinPath := labelPath{name: twin.Name, fields: bson.M{child.field(): twin.ID}}
+		for field, id := range target.fields {
+			twinPath.fields[field] = id
+		}
+		childMerge := LabelMerge{
+			Level:      child,
+			SourceID:   source.ID,
+			SourceName: source.Name,
+			TargetID:   twin.ID,
+			TargetName: twin.Name,
+			MergedAt:   merge.MergedAt,
+		}
+		if err := db.mergeLabels(ctx, child, twinPath, &childMerge); err != nil {
+			return err
+		}
+	}
+
+	update := bson.M{"$set": bson.M{level.field(): merge.TargetID}}
+	_, err = collection.UpdateMany(ctx, bson.M{level.field(): merge.SourceID}, update)
+
+	return err
+}
+
+// foldLeaderboardEntries adds the leaderboard entries of the source to the entries of the target
+// for the same user and window, and deletes them. Subtopics have no leaderboards.
+func (db *MongoDB) foldLeaderboardEntries(ctx mongo.SessionContext, level LabelLevel, merge *LabelMerge) error {
+	if level == SubtopicLevel {
+		return nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("leaderboard_entries")
+	sourceScope := leaderboardScope(string(level), merge.SourceID)
+	targetScope := leaderboardScope(string(level), merge.TargetID)
+
+	cursor, err := collection.Find(ctx, bson.M{"scope": sourceScope})
+	if err != nil {
+		return err
+	}
+	var entries []LeaderboardEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(entries))
+	for _, entry := range entries {
+		filter := bson.M{"user_id": entry.UserID, "scope": targetScope, "window": entry.Window}
+		update := bson.M{"$inc": bson.M{
+			"correct_first_attempts": entry.CorrectFirstAttempts,
+			"score":                  entry.Score,
+		}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+	if _, err := collection.BulkWrite(ctx, models); err != nil {
+		return err
+	}
+
+	_, err = collection.DeleteMany(ctx, bson.M{"scope": sourceScope})
+
+	return err
+}
+
+// DedupeLabels merges labels whose names only differ by case or accents within the same parent,
+// which keep the unique label indexes from being created, into the oldest of them, and creates
+// the indexes again. Subjects are deduplicated first, since merging them merges their topics.
+func (db *MongoDB) DedupeLabels() error {
+	parents := map[LabelLevel]string{
+		SubjectLevel:  "language",
+		TopicLevel:    SubjectLevel.field(),
+		SubtopicLevel: TopicLevel.field(),
+	}
+
+	for _, level := range []LabelLevel{SubjectLevel, TopicLevel, SubtopicLevel} {
+		duplicates, err := db.duplicateLabels(level, parents[level])
+		if err != nil {
+			return err
+		}
+
+		for _, ids := range duplicates {
+			for _, id := range ids[1:] {
+				merge, err := db.MergeLabels(level, id, MergeLabelParams{TargetID: ids[0]})
+				if err != nil {
+					return errors.New("cannot merge " + string(level) + " " + id + ": " + err.Error())
+				}
+				log.Println("merged", level, merge.SourceName, "into", merge.TargetName)
+			}
+		}
+	}
+
+	return db.ensureIndexes()
+}
+
+// duplicateLabels returns the ids of the labels of the level sharing a name within their parent,
+// oldest first, grouped by name.
+func (db *MongoDB) duplicateLabels(level LabelLevel, parent string) ([][]string, error) {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection(level.collection())
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"parent": "$" + parent, "name": "$name"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+	aggregateOptions := options.Aggregate().SetCollation(labelNameCollation)
+	cursor, err := collection.Aggregate(context.Background(), pipeline, aggregateOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(context.Background(), &groups); err != nil {
+		return nil, err
+	}
+
+	duplicates := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		ids := make([]string, 0, len(group.IDs))
+		for _, id := range group.IDs {
+			ids = append(ids, id.Hex())
+		}
+		duplicates = append(duplicates, ids)
+	}
+
+	return duplicates, nil
+}
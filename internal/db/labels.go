@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type LabelsDatabase interface {
@@ -21,6 +24,31 @@ type LabelsDatabase interface {
 	CreateSubtopic(arg CreateSubtopicParams) (Subtopic, error)
 	GetSubtopic(id string) (Subtopic, error)
 	ListSubtopics(arg ListSubtopicsParams) ([]Subtopic, error)
+
+	RenameLabel(level LabelLevel, id string, arg RenameLabelParams) error
+	DeleteLabel(level LabelLevel, id string, reassignTo string) error
+	MergeLabels(level LabelLevel, sourceID string, arg MergeLabelParams) (LabelMerge, error)
+}
+
+// labelNameCollation compares label names ignoring case and accents,
+// so "Funções" and "funcoes" are the same name.
+var labelNameCollation = &options.Collation{Locale: "en", Strength: 1}
+
+// labelIndexes make label names unique within their parent, a subject within its
+// language, a topic within its subject and a subtopic within its topic.
+var labelIndexes = map[string]mongo.IndexModel{
+	"subjects": {
+		Keys:    bson.D{{Key: "language", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true).SetCollation(labelNameCollation),
+	},
+	"topics": {
+		Keys:    bson.D{{Key: "subject_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true).SetCollation(labelNameCollation),
+	},
+	"subtopics": {
+		Keys:    bson.D{{Key: "topic_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true).SetCollation(labelNameCollation),
+	},
 }
 
 type CreateSubjectParams struct {
@@ -30,7 +58,7 @@ type CreateSubjectParams struct {
 
 func subjectFromParams(arg CreateSubjectParams) Subject {
 	return Subject{
-		Name:     arg.Name,
+		Name:     strings.TrimSpace(arg.Name),
 		Language: arg.Language,
 	}
 }
@@ -40,6 +68,12 @@ func (db *MongoDB) CreateSubject(arg CreateSubjectParams) (Subject, error) {
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("subjects")
 	result, err := collection.InsertOne(context.Background(), subject)
+	if mongo.IsDuplicateKeyError(err) {
+		return subject, errors.New("subject already exists")
+	}
+	if err != nil {
+		return subject, err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	subject.ID = id
@@ -81,7 +115,7 @@ type CreateTopicParams struct {
 
 func topicFromParams(arg CreateTopicParams) Topic {
 	return Topic{
-		Name:      arg.Name,
+		Name:      strings.TrimSpace(arg.Name),
 		SubjectID: arg.SubjectID,
 	}
 }
@@ -89,12 +123,14 @@ func topicFromParams(arg CreateTopicParams) Topic {
 func (db *MongoDB) CreateTopic(arg CreateTopicParams) (Topic, error) {
 	topic := topicFromParams(arg)
 
-	if !db.isUniqueTopic(topic.Name) {
-		return topic, errors.New("topic already exists")
-	}
-
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("topics")
 	result, err := collection.InsertOne(context.Background(), topic)
+	if mongo.IsDuplicateKeyError(err) {
+		return topic, errors.New("topic already exists")
+	}
+	if err != nil {
+		return topic, err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	topic.ID = id
@@ -102,16 +138,6 @@ func (db *MongoDB) CreateTopic(arg CreateTopicParams) (Topic, error) {
 	return topic, err
 }
 
-func (db *MongoDB) isUniqueTopic(topic string) bool {
-	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("topics")
-	filter := bson.D{{Key: "name", Value: topic}}
-	count, err := collection.CountDocuments(context.Background(), filter)
-	if err != nil {
-		return false
-	}
-	return count == 0
-}
-
 func (db *MongoDB) GetTopic(id string) (Topic, error) {
 	var topic Topic
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -151,7 +177,7 @@ type CreateSubtopicParams struct {
 
 func subtopicFromParams(arg CreateSubtopicParams) Subtopic {
 	return Subtopic{
-		Name:    arg.Name,
+		Name:    strings.TrimSpace(arg.Name),
 		TopicID: arg.TopicID,
 	}
 }
@@ -159,12 +185,14 @@ func subtopicFromParams(arg CreateSubtopicParams) Subtopic {
 func (db *MongoDB) CreateSubtopic(arg CreateSubtopicParams) (Subtopic, error) {
 	subtopic := subtopicFromParams(arg)
 
-	if !db.isUniqueSubtopic(subtopic.Name) {
-		return subtopic, errors.New("subtopic already exists")
-	}
-
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("subtopics")
 	result, err := collection.InsertOne(context.Background(), subtopic)
+	if mongo.IsDuplicateKeyError(err) {
+		return subtopic, errors.New("subtopic already exists")
+	}
+	if err != nil {
+		return subtopic, err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	subtopic.ID = id
@@ -172,16 +200,6 @@ func (db *MongoDB) CreateSubtopic(arg CreateSubtopicParams) (Subtopic, error) {
 	return subtopic, err
 }
 
-func (db *MongoDB) isUniqueSubtopic(subtopic string) bool {
-	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("subtopics")
-	filter := bson.D{{Key: "name", Value: subtopic}}
-	count, err := collection.CountDocuments(context.Background(), filter)
-	if err != nil {
-		return false
-	}
-	return count == 0
-}
-
 func (db *MongoDB) GetSubtopic(id string) (Subtopic, error) {
 	var subtopic Subtopic
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -0,0 +1,465 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LeaderboardDatabase interface {
+	GetLeaderboard(arg GetLeaderboardParams) (Leaderboard, error)
+	SetLeaderboardVisibility(arg SetLeaderboardVisibilityParams, userID string) error
+}
+
+const defaultLeaderboardSize = 10
+
+// leaderboardIndexes keep a single entry per user, scope and window, so concurrent
+// first attempts add to the same entry instead of creating one each.
+var leaderboardIndexes = map[string]mongo.IndexModel{
+	"leaderboard_entries": {
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "scope", Value: 1}, {Key: "window", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+}
+
+// leaderboardPoints is the score given to a first attempt with each solution accuracy.
+var leaderboardPoints = map[SolutionAccuracy]int{
+	Incorrect: 0,
+	Partial:   1,
+	Correct:   2,
+}
+
+func leaderboardScopes(attempt ProblemAttempt) []string {
+	scopes := []string{"global"}
+	if attempt.SubjectID != "" {
+		scopes = append(scopes, leaderboardScope("subject", attempt.SubjectID))
+	}
+	if attempt.TopicID != "" {
+		scopes = append(scopes, leaderboardScope("topic", attempt.TopicID))
+	}
+	return scopes
+}
+
+func leaderboardScope(scope string, labelID string) string {
+	if scope == "global" {
+		return scope
+	}
+	return scope + ":" + labelID
+}
+
+// leaderboardWindows returns the keys of the time windows that contain the given time.
+// Weeks and months are taken in UTC.
+func leaderboardWindows(t time.Time) []string {
+	return []string{
+		"all_time",
+		leaderboardWindow("weekly", t),
+		leaderboardWindow("monthly", t),
+	}
+}
+
+func leaderboardWindow(window string, t time.Time) string {
+	t = t.UTC()
+	switch window {
+	case "weekly":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("weekly:%d-W%02d", year, week)
+	case "monthly":
+		return fmt.Sprintf("monthly:%d-%02d", t.Year(), t.Month())
+	}
+	return "all_time"
+}
+
+// updateLeaderboards adds a first attempt to every leaderboard it counts toward.
+func (db *MongoDB) updateLeaderboards(attempt ProblemAttempt) error {
+	models := leaderboardUpdates(attempt)
+	if len(models) == 0 {
+		return nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("leaderboard_entries")
+	_, err := collection.BulkWrite(context.Background(), models)
+	if mongo.IsDuplicateKeyError(err) {
+		// two upserts raced to create the same entry, the retry finds the entry the other created
+		_, err = collection.BulkWrite(context.Background(), models)
+	}
+
+	return err
+}
+
+// leaderboardUpdates returns the updates adding the first attempt to every entry it counts toward.
+func leaderboardUpdates(attempt ProblemAttempt) []mongo.WriteModel {
+	correctFirstAttempts := 0
+	if attempt.SolutionAccuracy == Correct {
+		correctFirstAttempts = 1
+	}
+	score := leaderboardPoints[attempt.SolutionAccuracy]
+
+	if correctFirstAttempts == 0 && score == 0 {
+		return nil
+	}
+
+	var models []mongo.WriteModel
+	for _, scope := range leaderboardScopes(attempt) {
+		for _, window := range leaderboardWindows(attempt.AttemptedAt) {
+			filter := bson.M{"user_id": attempt.UserID, "scope": scope, "window": window}
+			update := bson.M{"$inc": bson.M{
+				"correct_first_attempts": correctFirstAttempts,
+				"score":                  score,
+			}}
+			model := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true)
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// BackfillLeaderboards adds to the leaderboards the first attempts made before the leaderboards
+// existed. Those attempts were stored without labels, so their labels are looked up on the problem
+// and stored on the attempt as it is counted, in the same transaction as the leaderboard entries.
+// Attempts counted as they were made are never counted again, so the backfill can run alongside
+// new attempts and can be run again safely.
+func (db *MongoDB) BackfillLeaderboards() error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	problemID := bson.M{"$convert": bson.M{"input": "$problem_id", "to": "objectId", "onError": nil}}
+	problemLabel := func(field string) bson.M {
+		return bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$problem." + field, 0}}, ""}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.M{"attempted_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"user_id": "$user_id", "problem_id": "$problem_id"},
+			"attempt": bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$attempt"}}},
+		{{Key: "$match", Value: bson.M{"subject_id": bson.M{"$exists": false}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "problems",
+			"let":  bson.M{"problem_id": problemID},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$problem_id"}}}}},
+				{{Key: "$project", Value: bson.M{"subject_id": 1, "topic_id": 1, "subtopic_id": 1}}},
+			},
+			"as": "problem",
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"subject_id":  problemLabel("subject_id"),
+			"topic_id":    problemLabel("topic_id"),
+			"subtopic_id": problemLabel("subtopic_id"),
+		}}},
+		{{Key: "$sort", Value: bson.M{"user_id": 1}}},
+	}
+	aggregateOptions := options.Aggregate().SetAllowDiskUse(true)
+	cursor, err := database.Collection("problem_attempts").Aggregate(context.Background(), pipeline, aggregateOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	userID := ""
+	var firstAttempts []ProblemAttempt
+	for cursor.Next(context.Background()) {
+		var attempt ProblemAttempt
+		if err := cursor.Decode(&attempt); err != nil {
+			return err
+		}
+
+		if attempt.UserID != userID && userID != "" {
+			if err := db.backfillUserLeaderboards(firstAttempts); err != nil {
+				return err
+			}
+			firstAttempts = nil
+		}
+		userID = attempt.UserID
+		firstAttempts = append(firstAttempts, attempt)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if userID == "" {
+		return nil
+	}
+	return db.backfillUserLeaderboards(firstAttempts)
+}
+
+// backfillUserLeaderboards counts the first attempts of a user in the leaderboards and stores their labels.
+func (db *MongoDB) backfillUserLeaderboards(firstAttempts []ProblemAttempt) error {
+	database := db.client.Database(os.Getenv("MONGODB_DB_NAME"))
+
+	var entries, attempts []mongo.WriteModel
+	for _, attempt := range firstAttempts {
+		objectID, err := primitive.ObjectIDFromHex(attempt.ID)
+		if err != nil {
+			return err
+		}
+		filter := bson.M{"_id": objectID, "subject_id": bson.M{"$exists": false}}
+		update := bson.M{"$set": bson.M{
+			"subject_id":  attempt.SubjectID,
+			"topic_id":    attempt.TopicID,
+			"subtopic_id": attempt.SubtopicID,
+		}}
+		attempts = append(attempts, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+
+		entries = append(entries, leaderboardUpdates(attempt)...)
+	}
+
+	session, err := db.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		if _, err := database.Collection("problem_attempts").BulkWrite(sessionContext, attempts); err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			return nil, nil
+		}
+		_, err := database.Collection("leaderboard_entries").BulkWrite(sessionContext, entries)
+		return nil, err
+	})
+
+	return err
+}
+
+type GetLeaderboardParams struct {
+	Scope   string `form:"scope" binding:"leaderboard_scope"`
+	LabelID string `form:"label_id" binding:"required_if=Scope subject,required_if=Scope topic"`
+	Window  string `form:"window" binding:"leaderboard_window"`
+	Metric  string `form:"metric" binding:"leaderboard_metric"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	UserID  string `form:"user_id"`
+}
+
+type LeaderboardRow struct {
+	Rank     int64  `json:"rank"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Value    int    `json:"value"`
+}
+
+type Leaderboard struct {
+	Scope   string           `json:"scope"`
+	Window  string           `json:"window"`
+	Metric  string           `json:"metric"`
+	Entries []LeaderboardRow `json:"entries"`
+	Caller  *LeaderboardRow  `json:"caller"`
+}
+
+// GetLeaderboard returns the top users of a leaderboard.
+// scope can be "global", "subject" or "topic", and the last two require a label id.
+// window can be "weekly", "monthly" or "all_time", and the weekly and monthly boards are the current ones.
+// metric can be "correct_first_attempts" or "score".
+// The caller is ranked even when outside of the top users. Users who opted out are never listed.
+func (db *MongoDB) GetLeaderboard(arg GetLeaderboardParams) (Leaderboard, error) {
+	if arg.Scope == "" {
+		arg.Scope = "global"
+	}
+	if arg.Window == "" {
+		arg.Window = "all_time"
+	}
+	if arg.Metric == "" {
+		arg.Metric = "correct_first_attempts"
+	}
+	if arg.Limit == 0 {
+		arg.Limit = defaultLeaderboardSize
+	}
+
+	leaderboard := Leaderboard{
+		Scope:   leaderboardScope(arg.Scope, arg.LabelID),
+		Window:  leaderboardWindow(arg.Window, time.Now()),
+		Metric:  arg.Metric,
+		Entries: []LeaderboardRow{},
+	}
+
+	hiddenUserIDs, err := db.hiddenLeaderboardUserIDs()
+	if err != nil {
+		return leaderboard, err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("leaderboard_entries")
+	filter := bson.M{
+		"scope":    leaderboard.Scope,
+		"window":   leaderboard.Window,
+		"user_id":  bson.M{"$nin": hiddenUserIDs},
+		arg.Metric: bson.M{"$gt": 0},
+	}
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: arg.Metric, Value: -1}, {Key: "user_id", Value: 1}})
+	findOptions.SetLimit(int64(arg.Limit))
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return leaderboard, err
+	}
+
+	var entries []LeaderboardEntry
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return leaderboard, err
+	}
+
+	for i, entry := range entries {
+		row := LeaderboardRow{
+			Rank:   int64(i + 1),
+			UserID: entry.UserID,
+			Value:  leaderboardValue(entry, arg.Metric),
+		}
+		if i > 0 && row.Value == leaderboard.Entries[i-1].Value {
+			row.Rank = leaderboard.Entries[i-1].Rank
+		}
+		leaderboard.Entries = append(leaderboard.Entries, row)
+	}
+
+	if arg.UserID != "" {
+		leaderboard.Caller, err = db.callerLeaderboardRow(filter, arg)
+		if err != nil {
+			return leaderboard, err
+		}
+	}
+
+	err = db.addLeaderboardUsernames(&leaderboard)
+
+	return leaderboard, err
+}
+
+func leaderboardValue(entry LeaderboardEntry, metric string) int {
+	if metric == "score" {
+		return entry.Score
+	}
+	return entry.CorrectFirstAttempts
+}
+
+func (db *MongoDB) callerLeaderboardRow(filter bson.M, arg GetLeaderboardParams) (*LeaderboardRow, error) {
+	var entry LeaderboardEntry
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("leaderboard_entries")
+	callerFilter := bson.M{"scope": filter["scope"], "window": filter["window"], "user_id": arg.UserID}
+	err := collection.FindOne(context.Background(), callerFilter).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value := leaderboardValue(entry, arg.Metric)
+	ahead := bson.M{}
+	for key, condition := range filter {
+		ahead[key] = condition
+	}
+	ahead[arg.Metric] = bson.M{"$gt": value}
+
+	usersAhead, err := collection.CountDocuments(context.Background(), ahead)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LeaderboardRow{
+		Rank:   usersAhead + 1,
+		UserID: arg.UserID,
+		Value:  value,
+	}, nil
+}
+
+func (db *MongoDB) hiddenLeaderboardUserIDs() ([]string, error) {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter := bson.M{"hide_from_leaderboards": true}
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if err := cursor.All(context.Background(), &users); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	return userIDs, nil
+}
+
+func (db *MongoDB) addLeaderboardUsernames(leaderboard *Leaderboard) error {
+	rows := make([]*LeaderboardRow, 0, len(leaderboard.Entries)+1)
+	for i := range leaderboard.Entries {
+		rows = append(rows, &leaderboard.Entries[i])
+	}
+	if leaderboard.Caller != nil {
+		rows = append(rows, leaderboard.Caller)
+	}
+
+	var userIDs []primitive.ObjectID
+	for _, row := range rows {
+		objectID, err := primitive.ObjectIDFromHex(row.UserID)
+		if err != nil {
+			continue
+		}
+		userIDs = append(userIDs, objectID)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter := bson.M{"_id": bson.M{"$in": userIDs}}
+	findOptions := options.Find().SetProjection(bson.M{"username": 1})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return err
+	}
+
+	var users []User
+	if err := cursor.All(context.Background(), &users); err != nil {
+		return err
+	}
+
+	usernames := make(map[string]string)
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+	for _, row := range rows {
+		row.Username = usernames[row.UserID]
+	}
+
+	return nil
+}
+
+type SetLeaderboardVisibilityParams struct {
+	Hidden *bool `json:"hidden" binding:"required"`
+}
+
+func (db *MongoDB) SetLeaderboardVisibility(arg SetLeaderboardVisibilityParams, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"hide_from_leaderboards": *arg.Hidden}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
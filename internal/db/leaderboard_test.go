@@ -0,0 +1,57 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLeaderboardWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   string
+		time     time.Time
+		expected string
+	}{
+		{"all time", "all_time", time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC), "all_time"},
+		{"weekly", "weekly", time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC), "weekly:2023-W19"},
+		{"monthly", "monthly", time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC), "monthly:2023-05"},
+		{"week belonging to the next year", "weekly", time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC), "weekly:2025-W01"},
+		{"week belonging to the previous year", "weekly", time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC), "weekly:2020-W53"},
+		{"taken in utc", "monthly", time.Date(2023, 6, 1, 1, 0, 0, 0, time.FixedZone("UTC+3", 3*60*60)), "monthly:2023-05"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := leaderboardWindow(test.window, test.time)
+			if window != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, window)
+			}
+		})
+	}
+}
+
+func TestLeaderboardScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempt  ProblemAttempt
+		expected []string
+	}{
+		{"without labels", ProblemAttempt{}, []string{"global"}},
+		{"with a subject", ProblemAttempt{SubjectID: "math"}, []string{"global", "subject:math"}},
+		{
+			name:     "with a subject and a topic",
+			attempt:  ProblemAttempt{SubjectID: "math", TopicID: "algebra", SubtopicID: "equations"},
+			expected: []string{"global", "subject:math", "topic:algebra"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scopes := leaderboardScopes(test.attempt)
+			if !reflect.DeepEqual(scopes, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, scopes)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ListSessionDatabase interface {
+	StartListSession(arg StartListSessionParams, listID string) (ListSessionState, error)
+	GetListSession(id string) (ListSessionState, error)
+	GetListSessionSummary(id string) (ListSessionSummary, error)
+}
+
+type StartListSessionParams struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// ListSessionState is a session with the next problem to answer.
+// The start token must be sent with the solve call of the next problem.
+type ListSessionState struct {
+	Session     ListSession  `json:"session"`
+	NextProblem interface{}  `json:"next_problem"`
+	StartToken  AttemptStart `json:"start"`
+}
+
+// StartListSession starts a session on the list, or resumes the session the user has in progress.
+func (db *MongoDB) StartListSession(arg StartListSessionParams, listID string) (ListSessionState, error) {
+	var session ListSession
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("list_sessions")
+	filter := bson.M{"list_id": listID, "user_id": arg.UserID, "status": SessionInProgress}
+	err := collection.FindOne(context.Background(), filter).Decode(&session)
+	if err == nil {
+		return db.listSessionState(session)
+	}
+	if err != mongo.ErrNoDocuments {
+		return ListSessionState{}, err
+	}
+
+	problemList, err := db.GetVisibleProblemList(listID, arg.UserID)
+	if err != nil {
+		return ListSessionState{}, err
+	}
+
+	problemIDs, err := db.visibleProblemIDs(problemList.ProblemIDs, arg.UserID)
+	if err != nil {
+		return ListSessionState{}, err
+	}
+
+	session = ListSession{
+		ListID:     listID,
+		UserID:     arg.UserID,
+		ProblemIDs: problemIDs,
+		Answers:    []ListSessionAnswer{},
+		Status:     SessionInProgress,
+		StartedAt:  time.Now(),
+	}
+	if len(session.ProblemIDs) == 0 {
+		session.Status = SessionFinished
+		session.FinishedAt = session.StartedAt
+	}
+
+	result, err := collection.InsertOne(context.Background(), session)
+	if err != nil {
+		return ListSessionState{}, err
+	}
+	session.ID = result.InsertedID.(primitive.ObjectID).Hex()
+
+	return db.listSessionState(session)
+}
+
+func (db *MongoDB) getListSession(id string) (ListSession, error) {
+	var session ListSession
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return session, errors.New("list session not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("list_sessions")
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return session, errors.New("list session not found")
+	}
+
+	return session, err
+}
+
+// GetListSession returns the session with its next problem, so the user can resume it after a disconnect.
+func (db *MongoDB) GetListSession(id string) (ListSessionState, error) {
+	session, err := db.getListSession(id)
+	if err != nil {
+		return ListSessionState{}, err
+	}
+
+	return db.listSessionState(session)
+}
+
+func (db *MongoDB) listSessionState(session ListSession) (ListSessionState, error) {
+	state := ListSessionState{Session: session}
+	if session.Status == SessionFinished {
+		return state, nil
+	}
+
+	problemID := session.ProblemIDs[len(session.Answers)]
+	problem, err := db.GetProblem(problemID)
+	if err == mongo.ErrNoDocuments {
+		session, err = db.dropDeletedSessionProblems(session)
+		if err != nil {
+			return state, err
+		}
+		return db.listSessionState(session)
+	}
+	if err != nil {
+		return state, err
+	}
+	state.NextProblem, err = db.hideExamAnswer(session.UserID, problem)
+	if err != nil {
+		return state, err
+	}
+
+	start := StartProblemAttemptParams{UserID: session.UserID}
+	state.StartToken, err = db.StartProblemAttempt(start, problemID)
+
+	return state, err
+}
+
+// dropDeletedSessionProblems removes the deleted problems the session has not reached yet,
+// finishing the session when none is left, and returns the session as it is stored.
+func (db *MongoDB) dropDeletedSessionProblems(session ListSession) (ListSession, error) {
+	answered := len(session.Answers)
+	remaining := session.ProblemIDs[answered:]
+
+	var objectIDs []primitive.ObjectID
+	for _, problemID := range remaining {
+		if objectID, err := primitive.ObjectIDFromHex(problemID); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	existing := make(map[string]bool)
+	if len(objectIDs) > 0 {
+		collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
+		ids, err := collection.Distinct(context.Background(), "_id", bson.M{"_id": bson.M{"$in": objectIDs}})
+		if err != nil {
+			return session, err
+		}
+		for _, id := range ids {
+			if objectID, ok := id.(primitive.ObjectID); ok {
+				existing[objectID.Hex()] = true
+			}
+		}
+	}
+
+	problemIDs := append([]string{}, session.ProblemIDs[:answered]...)
+	for _, problemID := range remaining {
+		if existing[problemID] {
+			problemIDs = append(problemIDs, problemID)
+		}
+	}
+
+	set := bson.M{"problem_ids": problemIDs}
+	if len(problemIDs) == answered {
+		set["status"] = SessionFinished
+		set["finished_at"] = time.Now()
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(session.ID)
+	if err != nil {
+		return session, err
+	}
+
+	// the update only applies while no answer was added, otherwise the stored session is used as is
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("list_sessions")
+	filter := bson.M{"_id": objectID, "status": SessionInProgress, "answers": bson.M{"$size": answered}}
+	_, err = collection.UpdateOne(context.Background(), filter, bson.M{"$set": set})
+	if err != nil {
+		return session, err
+	}
+
+	return db.getListSession(session.ID)
+}
+
+// checkListSessionAnswer verifies that the attempt answers the next problem of the session.
+func (db *MongoDB) checkListSessionAnswer(arg SolveProblemParams) error {
+	session, err := db.getListSession(arg.SessionID)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != arg.UserID {
+		return errors.New("list session not found")
+	}
+	if session.Status == SessionFinished {
+		return errors.New("list session already finished")
+	}
+	if session.ProblemIDs[len(session.Answers)] != arg.ProblemID {
+		return errors.New("problem is not the next one in the list session")
+	}
+
+	return nil
+}
+
+// recordListSessionAnswer adds the answer to the session, finishing it after the last problem.
+// The update only applies while the session still expects this answer, so a problem is never answered twice.
+func (db *MongoDB) recordListSessionAnswer(arg SolveProblemParams, attempt ProblemAttempt) error {
+	session, err := db.getListSession(arg.SessionID)
+	if err != nil {
+		return err
+	}
+
+	answered := len(session.Answers)
+	answer := ListSessionAnswer{
+		ProblemID:        attempt.ProblemID,
+		AttemptID:        attempt.ID,
+		SolutionAccuracy: attempt.SolutionAccuracy,
+		DurationMs:       attempt.DurationMs,
+		AnsweredAt:       attempt.AttemptedAt,
+	}
+
+	set := bson.M{}
+	if answered+1 == len(session.ProblemIDs) {
+		set["status"] = SessionFinished
+		set["finished_at"] = attempt.AttemptedAt
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(session.ID)
+	if err != nil {
+		return err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("list_sessions")
+	filter := bson.M{"_id": objectID, "status": SessionInProgress, "answers": bson.M{"$size": answered}}
+	update := bson.M{"$push": bson.M{"answers": answer}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("problem is not the next one in the list session")
+	}
+
+	return nil
+}
+
+type ListSessionSummary struct {
+	SessionID  string              `json:"session_id"`
+	ListID     string              `json:"list_id"`
+	Status     ListSessionStatus   `json:"status"`
+	Score      float64             `json:"score"`
+	MaxScore   int                 `json:"max_score"`
+	Answered   int                 `json:"answered"`
+	DurationMs int64               `json:"duration_ms"`
+	Answers    []ListSessionAnswer `json:"answers"`
+}
+
+// GetListSessionSummary returns the score of the session, where a correct answer is worth one point
+// and a partial answer half a point, along with the correctness of each answered problem.
+func (db *MongoDB) GetListSessionSummary(id string) (ListSessionSummary, error) {
+	session, err := db.getListSession(id)
+	if err != nil {
+		return ListSessionSummary{}, err
+	}
+
+	summary := ListSessionSummary{
+		SessionID: session.ID,
+		ListID:    session.ListID,
+		Status:    session.Status,
+		MaxScore:  len(session.ProblemIDs),
+		Answered:  len(session.Answers),
+		Answers:   session.Answers,
+	}
+
+	for _, answer := range session.Answers {
+		summary.Score += attemptScore(answer.SolutionAccuracy)
+	}
+
+	finishedAt := time.Now()
+	if session.Status == SessionFinished {
+		finishedAt = session.FinishedAt
+	}
+	summary.DurationMs = finishedAt.Sub(session.StartedAt).Milliseconds()
+
+	return summary, nil
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MistakeNotebookDatabase interface {
+	ListMistakes(userID string, arg ListMistakesParams) ([]MistakeNotebookEntry, error)
+	ExportMistakesToList(userID string, arg ExportMistakesParams) (ProblemList, error)
+}
+
+type MistakeNotebookEntry struct {
+	Problem          interface{}      `json:"problem"`
+	SolutionAccuracy SolutionAccuracy `json:"solution_accuracy"`
+	AttemptedAt      time.Time        `json:"attempted_at"`
+}
+
+type ListMistakesParams struct {
+	PaginationParams
+	SubjectFilter string
+}
+
+// ListMistakes returns the problems whose most recent attempt by the user was incorrect or partial.
+// The problems failed longest ago come first. Deleted problems and problems the user can no longer
+// see are dropped before paginating, so every page but the last is full.
+func (db *MongoDB) ListMistakes(userID string, arg ListMistakesParams) ([]MistakeNotebookEntry, error) {
+	pipeline := append(mistakesPipeline(userID, arg.SubjectFilter),
+		bson.D{{Key: "$skip", Value: arg.Skip}},
+		bson.D{{Key: "$limit", Value: arg.Limit}},
+	)
+
+	mistakes, err := db.mistakes(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]AnyProblem, 0, len(mistakes))
+	for _, mistake := range mistakes {
+		problems = append(problems, mistake.Problem)
+	}
+	views, err := db.HideExamAnswers(userID, problems)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]MistakeNotebookEntry, 0, len(mistakes))
+	for i, mistake := range mistakes {
+		entries = append(entries, MistakeNotebookEntry{
+			Problem:          views[i],
+			SolutionAccuracy: mistake.LastSolutionAccuracy,
+			AttemptedAt:      mistake.LastReviewedAt,
+		})
+	}
+
+	return entries, nil
+}
+
+type notebookMistake struct {
+	Problem              AnyProblem       `bson:"problem"`
+	LastSolutionAccuracy SolutionAccuracy `bson:"last_solution_accuracy"`
+	LastReviewedAt       time.Time        `bson:"last_reviewed_at"`
+}
+
+// mistakesPipeline matches the mistakes of the user along with their problems, the oldest first.
+// Deleted problems and problems the user can no longer see are dropped. The subject filter is
+// applied to the problem, since the subject copied to the history is not kept up to date.
+func mistakesPipeline(userID string, subjectFilter string) mongo.Pipeline {
+	problemID := bson.M{"$convert": bson.M{"input": "$problem_id", "to": "objectId", "onError": nil}}
+
+	problemFilter := visibleProblemsFilter(userID)
+	if subjectFilter != "" {
+		problemFilter["subject_id"] = subjectFilter
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"user_id":                userID,
+			"last_solution_accuracy": bson.M{"$in": []SolutionAccuracy{Incorrect, Partial}},
+		}}},
+		{{Key: "$sort", Value: bson.M{"last_reviewed_at": 1}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "problems",
+			"let":  bson.M{"problem_id": problemID},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$problem_id"}}}}},
+				{{Key: "$match", Value: problemFilter}},
+			},
+			"as": "problem",
+		}}},
+		{{Key: "$unwind", Value: "$problem"}},
+	}
+}
+
+func (db *MongoDB) mistakes(pipeline mongo.Pipeline) ([]notebookMistake, error) {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	cursor, err := collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var mistakes []notebookMistake
+	err = cursor.All(context.Background(), &mistakes)
+	return mistakes, err
+}
+
+type ExportMistakesParams struct {
+	Title         string `json:"title" binding:"required"`
+	Description   string `json:"description" binding:"required"`
+	Language      string `json:"language" binding:"required,language"`
+	SubjectFilter string `json:"subject_id"`
+}
+
+// ExportMistakesToList creates a problem list owned by the user with every problem in their mistake notebook
+// that still exists and that the user can see.
+func (db *MongoDB) ExportMistakesToList(userID string, arg ExportMistakesParams) (ProblemList, error) {
+	mistakes, err := db.mistakes(mistakesPipeline(userID, arg.SubjectFilter))
+	if err != nil {
+		return ProblemList{}, err
+	}
+
+	problemIDs := make([]string, 0, len(mistakes))
+	for _, mistake := range mistakes {
+		problemIDs = append(problemIDs, mistake.Problem.ID)
+	}
+
+	params := CreateProblemListParams{
+		CreatorID:   userID,
+		ProblemIDs:  problemIDs,
+		Title:       arg.Title,
+		Description: arg.Description,
+		SubjectID:   arg.SubjectFilter,
+		Language:    arg.Language,
+		Visibility:  Private,
+	}
+
+	return db.CreateProblemList(params)
+}
@@ -11,6 +11,28 @@ const (
 	MultipleSelection
 )
 
+func (problemType ProblemType) String() string {
+	switch problemType {
+	case TrueFalse:
+		return "true_false"
+	case MultipleTrueFalse:
+		return "multiple_true_false"
+	case MultipleChoice:
+		return "multiple_choice"
+	case MultipleSelection:
+		return "multiple_selection"
+	}
+	return "unknown"
+}
+
+type Visibility string
+
+const (
+	Public   Visibility = "public"
+	Unlisted Visibility = "unlisted"
+	Private  Visibility = "private"
+)
+
 type Problem struct {
 	ID             string      `json:"_id" bson:"_id,omitempty"`
 	ProblemType    ProblemType `json:"problem_type" bson:"problem_type"`
@@ -21,6 +43,10 @@ type Problem struct {
 	Upvotes        int         `json:"upvotes" bson:"upvotes"`
 	Downvotes      int         `json:"downvotes" bson:"downvotes"`
 
+	FirstAttempts        int     `json:"first_attempts" bson:"first_attempts"`
+	FirstCorrectAnswers  int     `json:"first_correct_answers" bson:"first_correct_answers"`
+	FirstAttemptAccuracy float64 `json:"first_attempt_accuracy" bson:"first_attempt_accuracy"`
+
 	Feedback         string `json:"feedback" bson:"feedback"`
 	SubjectID        string `json:"subject_id" bson:"subject_id"`
 	TopicID          string `json:"topic_id" bson:"topic_id"`
@@ -31,6 +57,8 @@ type Problem struct {
 	Statement       string `json:"statement" bson:"statement"`
 	CreatorID       string `json:"creator_id" bson:"creator_id"`
 	CreatorUsername string `json:"creator_username" bson:"creator_username"`
+
+	Visibility Visibility `json:"visibility" bson:"visibility"`
 }
 
 // TFProblem represents a True False Problem
@@ -69,6 +97,21 @@ type AnyProblem struct {
 	CorrectItems []bool   `json:"correct_items" bson:"correct_items,omitempty"`
 }
 
+// StrippedProblem is a problem without its answer key and feedback
+type StrippedProblem struct {
+	Problem `bson:"inline"`
+	Items   []string `json:"items" bson:"items,omitempty"`
+}
+
+func strippedProblem(problem AnyProblem) StrippedProblem {
+	stripped := StrippedProblem{
+		Problem: problem.Problem,
+		Items:   problem.Items,
+	}
+	stripped.Feedback = ""
+	return stripped
+}
+
 type User struct {
 	ID          string    `json:"_id" bson:"_id,omitempty"`
 	Email       string    `json:"email" bson:"email"`
@@ -81,6 +124,45 @@ type User struct {
 	CreatedLists       []string `json:"created_lists" bson:"created_lists"`
 	SolveLaterProblems []string `json:"solve_later_problems" bson:"solve_later_problems"`
 	SolveLaterLists    []string `json:"solve_later_lists" bson:"solve_later_lists"`
+	// SolveLaterDone holds the bookmarked problems the user solved correctly since bookmarking them
+	SolveLaterDone []string `json:"solve_later_done" bson:"solve_later_done"`
+
+	HideFromLeaderboards bool `json:"hide_from_leaderboards" bson:"hide_from_leaderboards"`
+
+	DailyGoal DailyGoal `json:"daily_goal" bson:"daily_goal"`
+	TimeZone  string    `json:"time_zone" bson:"time_zone"`
+	Streak    Streak    `json:"streak" bson:"streak"`
+
+	AcceptedSuggestions int `json:"accepted_suggestions" bson:"accepted_suggestions"`
+}
+
+type DailyGoalType string
+
+const (
+	ProblemsGoal       DailyGoalType = "problems"
+	CorrectAnswersGoal DailyGoalType = "correct_answers"
+)
+
+type DailyGoal struct {
+	Type   DailyGoalType `json:"type" bson:"type"`
+	Target int           `json:"target" bson:"target"`
+	// StreakFreezes is the number of missed days a streak can survive
+	StreakFreezes int `json:"streak_freezes" bson:"streak_freezes"`
+}
+
+type Streak struct {
+	Current          int    `json:"current" bson:"current"`
+	Longest          int    `json:"longest" bson:"longest"`
+	LastCompletedDay string `json:"last_completed_day" bson:"last_completed_day"`
+	FreezesUsed      int    `json:"freezes_used" bson:"freezes_used"`
+}
+
+type DailyProgress struct {
+	ID             string `json:"-" bson:"_id,omitempty"`
+	UserID         string `json:"user_id" bson:"user_id"`
+	Day            string `json:"day" bson:"day"`
+	Problems       int    `json:"problems" bson:"problems"`
+	CorrectAnswers int    `json:"correct_answers" bson:"correct_answers"`
 }
 
 type VoteStatus int
@@ -97,6 +179,25 @@ type UserProblemHistory struct {
 	ProblemID          string     `json:"problem_id" bson:"problem_id"`
 	ProblemAttemptsIDs []string   `json:"problem_attempts_ids" bson:"problem_attempts_ids"`
 	VoteStatus         VoteStatus `json:"vote_status" bson:"vote_status"`
+
+	Ease           float64   `json:"ease" bson:"ease"`
+	Interval       int       `json:"interval" bson:"interval"`
+	Repetitions    int       `json:"repetitions" bson:"repetitions"`
+	DueAt          time.Time `json:"due_at" bson:"due_at"`
+	LastReviewedAt time.Time `json:"last_reviewed_at" bson:"last_reviewed_at"`
+
+	// LastDueReviewAt is when the user last answered the problem while it was due for review,
+	// which is what counts toward the daily review limit
+	LastDueReviewAt time.Time `json:"last_due_review_at" bson:"last_due_review_at,omitempty"`
+
+	// CooldownUntil is when the user can attempt the problem again
+	CooldownUntil time.Time `json:"cooldown_until" bson:"cooldown_until,omitempty"`
+
+	LastSolutionAccuracy SolutionAccuracy `json:"last_solution_accuracy" bson:"last_solution_accuracy"`
+	SubjectID            string           `json:"subject_id" bson:"subject_id"`
+
+	// Solved is set once the user answers the problem correctly
+	Solved bool `json:"solved" bson:"solved"`
 }
 
 type SolutionAccuracy int
@@ -107,12 +208,37 @@ const (
 	Correct
 )
 
+func (solutionAccuracy SolutionAccuracy) String() string {
+	switch solutionAccuracy {
+	case Incorrect:
+		return "incorrect"
+	case Partial:
+		return "partial"
+	case Correct:
+		return "correct"
+	}
+	return "unknown"
+}
+
 type ProblemAttempt struct {
 	ID               string           `json:"_id" bson:"_id,omitempty"`
 	UserID           string           `json:"user_id" bson:"user_id"`
 	ProblemID        string           `json:"problem_id" bson:"problem_id"`
 	AttemptedAt      time.Time        `json:"attempted_at" bson:"attempted_at"`
 	SolutionAccuracy SolutionAccuracy `json:"solution_accuracy" bson:"solution_accuracy"`
+	DurationMs       int64            `json:"duration_ms" bson:"duration_ms"`
+	TooFast          bool             `json:"too_fast" bson:"too_fast"`
+
+	SubjectID  string `json:"subject_id" bson:"subject_id"`
+	TopicID    string `json:"topic_id" bson:"topic_id"`
+	SubtopicID string `json:"subtopic_id" bson:"subtopic_id"`
+}
+
+type AttemptStart struct {
+	ID        string    `json:"start_token" bson:"_id,omitempty"`
+	UserID    string    `json:"user_id" bson:"user_id"`
+	ProblemID string    `json:"problem_id" bson:"problem_id"`
+	StartedAt time.Time `json:"started_at" bson:"started_at"`
 }
 
 type TFProblemAttempt struct {
@@ -135,15 +261,209 @@ type MSProblemAttempt struct {
 	ItemResponses  []bool `json:"item_responses" bson:"item_responses"`
 }
 
+type AnyProblemAttempt struct {
+	ProblemAttempt `bson:"inline"`
+	BoolResponse   bool   `json:"bool_response" bson:"bool_response,omitempty"`
+	BoolResponses  []bool `json:"bool_responses" bson:"bool_responses,omitempty"`
+	ItemResponse   int    `json:"item_response" bson:"item_response,omitempty"`
+	ItemResponses  []bool `json:"item_responses" bson:"item_responses,omitempty"`
+}
+
+type UserAchievement struct {
+	ID            string    `json:"_id" bson:"_id,omitempty"`
+	UserID        string    `json:"user_id" bson:"user_id"`
+	AchievementID string    `json:"achievement_id" bson:"achievement_id"`
+	Name          string    `json:"name" bson:"name"`
+	Description   string    `json:"description" bson:"description"`
+	EarnedAt      time.Time `json:"earned_at" bson:"earned_at"`
+}
+
+type LeaderboardEntry struct {
+	ID                   string `json:"-" bson:"_id,omitempty"`
+	UserID               string `json:"user_id" bson:"user_id"`
+	Scope                string `json:"scope" bson:"scope"`
+	Window               string `json:"window" bson:"window"`
+	CorrectFirstAttempts int    `json:"correct_first_attempts" bson:"correct_first_attempts"`
+	Score                int    `json:"score" bson:"score"`
+}
+
+type ListKind string
+
+const (
+	StaticList  ListKind = "static"
+	DynamicList ListKind = "dynamic"
+)
+
 type ProblemList struct {
-	ID          string    `json:"_id" bson:"_id,omitempty"`
-	CreatorID   string    `json:"creator_id" bson:"creator_id"`
-	ProblemIDs  []string  `json:"problem_ids" bson:"problem_ids"`
-	Description string    `json:"description" bson:"description"`
-	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	ID          string     `json:"_id" bson:"_id,omitempty"`
+	CreatorID   string     `json:"creator_id" bson:"creator_id"`
+	Kind        ListKind   `json:"kind" bson:"kind"`
+	Visibility  Visibility `json:"visibility" bson:"visibility"`
+	ProblemIDs  []string   `json:"problem_ids" bson:"problem_ids"`
+	Title       string     `json:"title" bson:"title"`
+	Description string     `json:"description" bson:"description"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
+
+	SubjectID        string `json:"subject_id" bson:"subject_id"`
+	LevelOfEducation string `json:"level_of_education" bson:"level_of_education"`
+	Language         string `json:"language" bson:"language"`
 
 	Upvotes   int `json:"upvotes" bson:"upvotes"`
 	Downvotes int `json:"downvotes" bson:"downvotes"`
+
+	// ForkedFrom is the id of the list this list was copied from
+	ForkedFrom string `json:"forked_from" bson:"forked_from,omitempty"`
+	ForkCount  int    `json:"fork_count" bson:"fork_count"`
+
+	Collaborators []ListCollaborator `json:"collaborators" bson:"collaborators"`
+
+	// Query is only set for dynamic lists, whose problems are the results of the query
+	Query *ListQuery `json:"query" bson:"query,omitempty"`
+
+	// Exam is only set for lists used as a timed exam
+	Exam *ExamSettings `json:"exam" bson:"exam,omitempty"`
+}
+
+// ListQuery is the saved search of a dynamic list.
+// MaxSize caps the number of problems, up to maxDynamicListSize.
+type ListQuery struct {
+	ListProblemsParams `bson:",inline"`
+	MaxSize            int `json:"max_size" bson:"max_size" binding:"omitempty,min=1,max=200"`
+}
+
+type RevealAnswers string
+
+const (
+	RevealImmediately     RevealAnswers = "immediately"
+	RevealAfterSubmission RevealAnswers = "after_submission"
+)
+
+type ExamSettings struct {
+	DurationMinutes int           `json:"duration_minutes" bson:"duration_minutes"`
+	AllowedAttempts int           `json:"allowed_attempts" bson:"allowed_attempts"`
+	RevealAnswers   RevealAnswers `json:"reveal_answers" bson:"reveal_answers"`
+}
+
+type ExamAttemptStatus string
+
+const (
+	ExamInProgress ExamAttemptStatus = "in_progress"
+	ExamSubmitted  ExamAttemptStatus = "submitted"
+	ExamExpired    ExamAttemptStatus = "expired"
+)
+
+// ExamAttempt is a user taking a problem list as an exam.
+// The settings and problems of the list are copied when the attempt starts.
+// Answers are keyed by problem id.
+type ExamAttempt struct {
+	ID          string                `json:"_id" bson:"_id,omitempty"`
+	ListID      string                `json:"list_id" bson:"list_id"`
+	UserID      string                `json:"user_id" bson:"user_id"`
+	Settings    ExamSettings          `json:"settings" bson:"settings"`
+	ProblemIDs  []string              `json:"problem_ids" bson:"problem_ids"`
+	Answers     map[string]ExamAnswer `json:"answers" bson:"answers"`
+	Status      ExamAttemptStatus     `json:"status" bson:"status"`
+	StartedAt   time.Time             `json:"started_at" bson:"started_at"`
+	Deadline    time.Time             `json:"deadline" bson:"deadline"`
+	SubmittedAt time.Time             `json:"submitted_at" bson:"submitted_at,omitempty"`
+	Score       float64               `json:"score" bson:"score"`
+	MaxScore    int                   `json:"max_score" bson:"max_score"`
+}
+
+// ExamAnswer holds the response matching the type of the problem.
+// SolutionAccuracy is only set once the answer is graded.
+// ProblemResponse is the response to a problem of any type,
+// where only the field matching the type of the problem is set.
+type ProblemResponse struct {
+	BoolResponse  *bool  `json:"bool_response,omitempty" bson:"bool_response,omitempty"`
+	BoolResponses []bool `json:"bool_responses,omitempty" bson:"bool_responses,omitempty"`
+	ItemResponse  *int   `json:"item_response,omitempty" bson:"item_response,omitempty"`
+	ItemResponses []bool `json:"item_responses,omitempty" bson:"item_responses,omitempty"`
+}
+
+type ExamAnswer struct {
+	ProblemResponse  `bson:",inline"`
+	SavedAt          time.Time         `json:"saved_at" bson:"saved_at"`
+	SolutionAccuracy *SolutionAccuracy `json:"solution_accuracy,omitempty" bson:"solution_accuracy,omitempty"`
+}
+
+// ListRole is the access of a user to a list. Each role includes the permissions of the roles below it.
+type ListRole string
+
+const (
+	NoListRole ListRole = ""
+	ViewerRole ListRole = "viewer"
+	EditorRole ListRole = "editor"
+	OwnerRole  ListRole = "owner"
+)
+
+func (role ListRole) rank() int {
+	switch role {
+	case ViewerRole:
+		return 1
+	case EditorRole:
+		return 2
+	case OwnerRole:
+		return 3
+	}
+	return 0
+}
+
+// Includes reports whether the role grants the permissions of the other role.
+func (role ListRole) Includes(other ListRole) bool {
+	return role.rank() >= other.rank()
+}
+
+type CollaboratorStatus string
+
+const (
+	CollaboratorInvited  CollaboratorStatus = "invited"
+	CollaboratorAccepted CollaboratorStatus = "accepted"
+)
+
+// ListCollaborator is a user invited to a list. The role only applies once the invitation is accepted.
+// The creator of the list is its owner and is never a collaborator.
+type ListCollaborator struct {
+	UserID     string             `json:"user_id" bson:"user_id"`
+	Role       ListRole           `json:"role" bson:"role"`
+	Status     CollaboratorStatus `json:"status" bson:"status"`
+	InvitedAt  time.Time          `json:"invited_at" bson:"invited_at"`
+	AcceptedAt time.Time          `json:"accepted_at" bson:"accepted_at,omitempty"`
+}
+
+type UserListVote struct {
+	ID         string     `json:"_id" bson:"_id,omitempty"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	ListID     string     `json:"list_id" bson:"list_id"`
+	VoteStatus VoteStatus `json:"vote_status" bson:"vote_status"`
+}
+
+type ListSessionStatus string
+
+const (
+	SessionInProgress ListSessionStatus = "in_progress"
+	SessionFinished   ListSessionStatus = "finished"
+)
+
+// ListSession is a guided run through a problem list, answering its problems in order.
+// The problems of the list are copied when the session starts, so later edits to the list do not affect it.
+type ListSession struct {
+	ID         string              `json:"_id" bson:"_id,omitempty"`
+	ListID     string              `json:"list_id" bson:"list_id"`
+	UserID     string              `json:"user_id" bson:"user_id"`
+	ProblemIDs []string            `json:"problem_ids" bson:"problem_ids"`
+	Answers    []ListSessionAnswer `json:"answers" bson:"answers"`
+	Status     ListSessionStatus   `json:"status" bson:"status"`
+	StartedAt  time.Time           `json:"started_at" bson:"started_at"`
+	FinishedAt time.Time           `json:"finished_at" bson:"finished_at,omitempty"`
+}
+
+type ListSessionAnswer struct {
+	ProblemID        string           `json:"problem_id" bson:"problem_id"`
+	AttemptID        string           `json:"attempt_id" bson:"attempt_id"`
+	SolutionAccuracy SolutionAccuracy `json:"solution_accuracy" bson:"solution_accuracy"`
+	DurationMs       int64            `json:"duration_ms" bson:"duration_ms"`
+	AnsweredAt       time.Time        `json:"answered_at" bson:"answered_at"`
 }
 
 type Subject struct {
@@ -164,6 +484,28 @@ type Subtopic struct {
 	TopicID string `json:"topic_id" bson:"topic_id"`
 }
 
+type LabelLevel string
+
+const (
+	SubjectLevel  LabelLevel = "subject"
+	TopicLevel    LabelLevel = "topic"
+	SubtopicLevel LabelLevel = "subtopic"
+)
+
+// LabelMerge records a label merged into another one, for audit.
+type LabelMerge struct {
+	ID         string     `json:"_id" bson:"_id,omitempty"`
+	Level      LabelLevel `json:"level" bson:"level"`
+	SourceID   string     `json:"source_id" bson:"source_id"`
+	SourceName string     `json:"source_name" bson:"source_name"`
+	TargetID   string     `json:"target_id" bson:"target_id"`
+	TargetName string     `json:"target_name" bson:"target_name"`
+	MergedAt   time.Time  `json:"merged_at" bson:"merged_at"`
+
+	// Problems is the number of problems re-tagged from the source to the target
+	Problems int `json:"problems" bson:"problems"`
+}
+
 type ProblemReport struct {
 	ID         string    `json:"_id" bson:"_id,omitempty"`
 	ProblemID  string    `json:"problem_id" bson:"problem_id"`
@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PracticeDatabase interface {
+	NextPracticeProblem(arg NextPracticeProblemParams) (PracticeProblem, error)
+}
+
+const (
+	// abilityAttempts is how many of the latest attempts are used to estimate the ability of the user
+	abilityAttempts = 200
+	// recentAttempts is how many of the latest attempts are never served again
+	recentAttempts = 20
+	// recentPeriod is how long an attempted problem is not served again
+	recentPeriod = 24 * time.Hour
+	// practiceCandidates is how many random candidates are scored to pick the next problem
+	practiceCandidates = 100
+	// weaknessWeight is how much a weak subtopic weighs against the difficulty fit of a problem
+	weaknessWeight = 0.5
+)
+
+type NextPracticeProblemParams struct {
+	UserID string `json:"user_id" binding:"required"`
+
+	SubjectFilter          string `json:"subject_id"`
+	TopicFilter            string `json:"topic_id"`
+	SubtopicFilter         string `json:"subtopic_id"`
+	LevelOfEducationFilter string `json:"level_of_education" binding:"level_of_education"`
+	LanguageFilter         string `json:"language" binding:"language"`
+}
+
+type PracticeProblem struct {
+	Problem          interface{} `json:"problem"`
+	Seen             bool        `json:"seen"`
+	EstimatedAbility float64     `json:"estimated_ability"`
+}
+
+type practiceProfile struct {
+	ability          float64
+	subtopicWeakness map[string]float64
+	recentProblemIDs []primitive.ObjectID
+	seenProblemIDs   []primitive.ObjectID
+}
+
+// attemptScore maps the solution accuracy of an attempt to a value between 0 and 1.
+func attemptScore(solutionAccuracy SolutionAccuracy) float64 {
+	return float64(solutionAccuracy) / float64(Correct)
+}
+
+// NextPracticeProblem picks the next problem to serve to the user.
+// Unseen problems are preferred. Among the candidates, the chosen problem is the one whose
+// difficulty is closest to the estimated ability of the user, with a bonus for the subtopics
+// where the user is weak. Recently attempted problems are never served.
+func (db *MongoDB) NextPracticeProblem(arg NextPracticeProblemParams) (PracticeProblem, error) {
+	var practice PracticeProblem
+
+	profile, err := db.practiceProfile(arg.UserID)
+	if err != nil {
+		return practice, err
+	}
+	practice.EstimatedAbility = profile.ability
+
+	candidates, err := db.practiceCandidates(arg, bson.M{"$nin": profile.seenProblemIDs})
+	if err != nil {
+		return practice, err
+	}
+
+	if len(candidates) == 0 {
+		practice.Seen = true
+		candidates, err = db.practiceCandidates(arg, bson.M{"$nin": profile.recentProblemIDs})
+		if err != nil {
+			return practice, err
+		}
+	}
+
+	if len(candidates) == 0 {
+		return practice, errors.New("no problem to practice")
+	}
+
+	var best AnyProblem
+	bestScore := math.Inf(-1)
+	for _, candidate := range candidates {
+		score := practiceScore(candidate, profile)
+		if score > bestScore {
+			bestScore = score
+			best = candidate
+		}
+	}
+
+	practice.Problem, err = db.hideExamAnswer(arg.UserID, best)
+
+	return practice, err
+}
+
+func practiceScore(problem AnyProblem, profile practiceProfile) float64 {
+	expectedSuccess := 0.5
+	if problem.FirstAttempts > 0 {
+		expectedSuccess = problem.FirstAttemptAccuracy
+	} else if problem.Attempts > 0 {
+		expectedSuccess = problem.Accuracy
+	}
+
+	difficulty := 1 - expectedSuccess
+	fit := 1 - math.Abs(difficulty-profile.ability)
+
+	weakness, ok := profile.subtopicWeakness[problem.SubtopicID]
+	if !ok {
+		weakness = 0.5
+	}
+
+	return fit + weaknessWeight*weakness
+}
+
+func (db *MongoDB) practiceProfile(userID string) (practiceProfile, error) {
+	profile := practiceProfile{
+		ability:          0.5,
+		subtopicWeakness: make(map[string]float64),
+		recentProblemIDs: []primitive.ObjectID{},
+		seenProblemIDs:   []primitive.ObjectID{},
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "attempted_at", Value: -1}})
+	findOptions.SetLimit(abilityAttempts)
+	findOptions.SetProjection(bson.M{"problem_id": 1, "subtopic_id": 1, "attempted_at": 1, "solution_accuracy": 1})
+
+	cursor, err := collection.Find(context.Background(), bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return profile, err
+	}
+
+	var attempts []ProblemAttempt
+	if err := cursor.All(context.Background(), &attempts); err != nil {
+		return profile, err
+	}
+
+	totalScore := 0.0
+	subtopicScores := make(map[string][]float64)
+	recentSince := time.Now().Add(-recentPeriod)
+	for i, attempt := range attempts {
+		score := attemptScore(attempt.SolutionAccuracy)
+		totalScore += score
+		if attempt.SubtopicID != "" {
+			subtopicScores[attempt.SubtopicID] = append(subtopicScores[attempt.SubtopicID], score)
+		}
+
+		if i < recentAttempts || attempt.AttemptedAt.After(recentSince) {
+			if objectID, err := primitive.ObjectIDFromHex(attempt.ProblemID); err == nil {
+				profile.recentProblemIDs = append(profile.recentProblemIDs, objectID)
+			}
+		}
+	}
+
+	if len(attempts) > 0 {
+		profile.ability = totalScore / float64(len(attempts))
+	}
+
+	for subtopicID, scores := range subtopicScores {
+		sum := 0.0
+		for _, score := range scores {
+			sum += score
+		}
+		profile.subtopicWeakness[subtopicID] = 1 - sum/float64(len(scores))
+	}
+
+	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	problemIDs, err := collection.Distinct(context.Background(), "problem_id", bson.M{"user_id": userID})
+	if err != nil {
+		return profile, err
+	}
+
+	for _, problemID := range problemIDs {
+		id, ok := problemID.(string)
+		if !ok {
+			continue
+		}
+		if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+			profile.seenProblemIDs = append(profile.seenProblemIDs, objectID)
+		}
+	}
+
+	return profile, nil
+}
+
+func (db *MongoDB) practiceCandidates(arg NextPracticeProblemParams, idCondition bson.M) ([]AnyProblem, error) {
+	match := bson.M{"_id": idCondition, "visibility": listedVisibility()}
+	if arg.SubjectFilter != "" {
+		match["subject_id"] = arg.SubjectFilter
+	}
+	if arg.TopicFilter != "" {
+		match["topic_id"] = arg.TopicFilter
+	}
+	if arg.SubtopicFilter != "" {
+		match["subtopic_id"] = arg.SubtopicFilter
+	}
+	if arg.LevelOfEducationFilter != "" {
+		match["level_of_education"] = arg.LevelOfEducationFilter
+	}
+	if arg.LanguageFilter != "" {
+		match["language"] = arg.LanguageFilter
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sample", Value: bson.M{"size": practiceCandidates}}},
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
+	cursor, err := collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []AnyProblem
+	err = cursor.All(context.Background(), &candidates)
+	return candidates, err
+}
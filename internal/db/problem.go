@@ -8,6 +8,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -23,6 +24,7 @@ type ProblemDatabase interface {
 	SolveMSProblem(arg SolveMSProblemParams) (MSProblemAttempt, error)
 
 	GetProblem(id string) (AnyProblem, error)
+	GetVisibleProblem(id string, userID string) (AnyProblem, error)
 	UpdateProblem(arg UpdateProblemParams, id string) error
 	DeleteProblem(id string) error
 	ListProblems(arg ListProblemsParams) ([]AnyProblem, error)
@@ -41,6 +43,8 @@ type CreateProblemParams struct {
 	Language         string `json:"language" binding:"required,language"`
 	CreatorID        string `json:"creator_id" binding:"required"`
 	CreatorUsername  string `json:"creator_username" binding:"required"`
+
+	Visibility Visibility `json:"visibility" binding:"omitempty,visibility"`
 }
 
 type CreateTFProblemParams struct {
@@ -67,6 +71,11 @@ type CreateMSProblemParams struct {
 }
 
 func problemFromCreateParams(arg CreateProblemParams, problemType ProblemType) Problem {
+	visibility := arg.Visibility
+	if visibility == "" {
+		visibility = Public
+	}
+
 	return Problem{
 		Statement:        arg.Statement,
 		Feedback:         arg.Feedback,
@@ -77,6 +86,7 @@ func problemFromCreateParams(arg CreateProblemParams, problemType ProblemType) P
 		Language:         arg.Language,
 		CreatorID:        arg.CreatorID,
 		CreatorUsername:  arg.CreatorUsername,
+		Visibility:       visibility,
 
 		ProblemType:    problemType,
 		CreatedAt:      time.Now(),
@@ -85,6 +95,10 @@ func problemFromCreateParams(arg CreateProblemParams, problemType ProblemType) P
 		Accuracy:       0.0,
 		Upvotes:        0,
 		Downvotes:      0,
+
+		FirstAttempts:        0,
+		FirstCorrectAnswers:  0,
+		FirstAttemptAccuracy: 0.0,
 	}
 }
 
@@ -122,10 +136,7 @@ func msProblemFromCreateParams(arg CreateMSProblemParams) MSProblem {
 func (db *MongoDB) CreateTFProblem(arg CreateTFProblemParams) (TFProblem, error) {
 	problem := tfProblemFromCreateParams(arg)
 
-	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
-	result, err := collection.InsertOne(context.Background(), problem)
-
-	id := result.InsertedID.(primitive.ObjectID).Hex()
+	id, err := db.insertProblem(problem, problem.Problem)
 	problem.ID = id
 
 	return problem, err
@@ -134,10 +145,7 @@ func (db *MongoDB) CreateTFProblem(arg CreateTFProblemParams) (TFProblem, error)
 func (db *MongoDB) CreateMTFProblem(arg CreateMTFProblemParams) (MTFProblem, error) {
 	problem := mtfProblemFromCreateParams(arg)
 
-	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
-	result, err := collection.InsertOne(context.Background(), problem)
-
-	id := result.InsertedID.(primitive.ObjectID).Hex()
+	id, err := db.insertProblem(problem, problem.Problem)
 	problem.ID = id
 
 	return problem, err
@@ -146,10 +154,7 @@ func (db *MongoDB) CreateMTFProblem(arg CreateMTFProblemParams) (MTFProblem, err
 func (db *MongoDB) CreateMCProblem(arg CreateMCProblemParams) (MCProblem, error) {
 	problem := mcProblemFromCreateParams(arg)
 
-	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
-	result, err := collection.InsertOne(context.Background(), problem)
-
-	id := result.InsertedID.(primitive.ObjectID).Hex()
+	id, err := db.insertProblem(problem, problem.Problem)
 	problem.ID = id
 
 	return problem, err
@@ -158,13 +163,27 @@ func (db *MongoDB) CreateMCProblem(arg CreateMCProblemParams) (MCProblem, error)
 func (db *MongoDB) CreateMSProblem(arg CreateMSProblemParams) (MSProblem, error) {
 	problem := msProblemFromCreateParams(arg)
 
+	id, err := db.insertProblem(problem, problem.Problem)
+	problem.ID = id
+
+	return problem, err
+}
+
+// insertProblem stores the problem and adds it to the problems created by its creator.
+func (db *MongoDB) insertProblem(problem interface{}, base Problem) (string, error) {
+	if err := db.checkLabels(base.SubjectID, base.TopicID, base.SubtopicID); err != nil {
+		return "", err
+	}
+
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
 	result, err := collection.InsertOne(context.Background(), problem)
+	if err != nil {
+		return "", err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
-	problem.ID = id
 
-	return problem, err
+	return id, db.addCreatedContent(base.CreatorID, "created_problems", id)
 }
 
 func (db *MongoDB) GetProblem(id string) (AnyProblem, error) {
@@ -187,6 +206,9 @@ type UpdateProblemParams struct {
 	SubtopicID       string `json:"subtopic_id"`
 	LevelOfEducation string `json:"level_of_education" binding:"level_of_education"`
 	Language         string `json:"language" binding:"language"`
+
+	// Visibility is only changed when present
+	Visibility *Visibility `json:"visibility" binding:"omitempty,visibility"`
 }
 
 func (db *MongoDB) UpdateProblem(arg UpdateProblemParams, id string) error {
@@ -195,40 +217,57 @@ func (db *MongoDB) UpdateProblem(arg UpdateProblemParams, id string) error {
 		return err
 	}
 
+	if err := db.checkLabels(arg.SubjectID, arg.TopicID, arg.SubtopicID); err != nil {
+		return err
+	}
+
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
 	filter := bson.M{"_id": objectID}
-	update := bson.M{"$set": bson.M{
+	fields := bson.M{
 		"feedback":           arg.Feedback,
 		"subject_id":         arg.SubjectID,
 		"topic_id":           arg.TopicID,
 		"subtopic_id":        arg.SubtopicID,
 		"level_of_education": arg.LevelOfEducation,
 		"language":           arg.Language,
-	}}
-	result, err := collection.UpdateOne(context.Background(), filter, update)
+	}
+	if arg.Visibility != nil {
+		fields["visibility"] = *arg.Visibility
+	}
+	result, err := collection.UpdateOne(context.Background(), filter, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
 
 	if result.MatchedCount == 0 {
 		return errors.New("problem not found")
 	}
 
-	return err
+	return nil
 }
 
 func (db *MongoDB) DeleteProblem(id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		return errors.New("problem not found")
 	}
 
+	var problem Problem
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
 	filter := bson.D{{Key: "_id", Value: objectID}}
-	result, err := collection.DeleteOne(context.Background(), filter)
-
-	if result.DeletedCount == 0 {
+	err = collection.FindOneAndDelete(context.Background(), filter).Decode(&problem)
+	if err == mongo.ErrNoDocuments {
 		return errors.New("problem not found")
 	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := db.deleteProblemReferences(id); err != nil {
+		return err
+	}
+
+	return db.removeCreatedContent(problem.CreatorID, "created_problems", id)
 }
 
 type PaginationParams struct {
@@ -237,24 +276,27 @@ type PaginationParams struct {
 }
 
 type ListProblemsParams struct {
-	PaginationParams
+	PaginationParams `json:"-" bson:"-"`
 
-	OrderBy    string `json:"order_by" binding:"field_to_order_problems"`
-	Descending *bool  `json:"descending"`
+	OrderBy    string `json:"order_by" bson:"order_by" binding:"field_to_order_problems"`
+	Descending *bool  `json:"descending" bson:"descending,omitempty"`
 
-	ProblemTypeFilter      *int   `json:"problem_type"`
-	SubjectFilter          string `json:"subject_id"`
-	TopicFilter            string `json:"topic_id"`
-	SubtopicFilter         string `json:"subtopic_id"`
-	LevelOfEducationFilter string `json:"level_of_education" binding:"level_of_education"`
-	LanguageFilter         string `json:"language" binding:"language"`
-	CreatorIDFilter        string `json:"creator_id"`
+	ProblemTypeFilter      *int   `json:"problem_type" bson:"problem_type,omitempty"`
+	SubjectFilter          string `json:"subject_id" bson:"subject_id"`
+	TopicFilter            string `json:"topic_id" bson:"topic_id"`
+	SubtopicFilter         string `json:"subtopic_id" bson:"subtopic_id"`
+	LevelOfEducationFilter string `json:"level_of_education" bson:"level_of_education" binding:"level_of_education"`
+	LanguageFilter         string `json:"language" bson:"language" binding:"language"`
+	CreatorIDFilter        string `json:"creator_id" bson:"creator_id"`
+	MinUpvotesFilter       *int   `json:"min_upvotes" bson:"min_upvotes,omitempty"`
 }
 
 // ListProblems returns a list of problems.
 // The returned list is ordered by the field specified in the `order_by` parameter.
-// order_by can be one of the following values: "created_at", "attempts", "accuracy", "upvotes"
+// order_by can be one of the following values: "created_at", "attempts", "accuracy", "upvotes",
+// "first_attempts", "first_attempt_accuracy"
 // The Filter can be empty. If a filter is empty, it is ignored.
+// Unlisted and private problems are never listed.
 func (db *MongoDB) ListProblems(arg ListProblemsParams) ([]AnyProblem, error) {
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
 	filter := filterFromParams(arg)
@@ -284,7 +326,7 @@ func (db *MongoDB) ListProblems(arg ListProblemsParams) ([]AnyProblem, error) {
 }
 
 func filterFromParams(arg ListProblemsParams) bson.M {
-	filter := bson.M{}
+	filter := bson.M{"visibility": listedVisibility()}
 
 	if arg.ProblemTypeFilter != nil {
 		filter["problem_type"] = arg.ProblemTypeFilter
@@ -307,6 +349,9 @@ func filterFromParams(arg ListProblemsParams) bson.M {
 	if arg.CreatorIDFilter != "" {
 		filter["creator_id"] = arg.CreatorIDFilter
 	}
+	if arg.MinUpvotesFilter != nil {
+		filter["upvotes"] = bson.M{"$gte": *arg.MinUpvotesFilter}
+	}
 
 	return filter
 }
@@ -336,18 +381,26 @@ func optionsFromParams(arg ListProblemsParams) *options.FindOptions {
 }
 
 type SolveProblemParams struct {
-	UserID    string `json:"user_id" binding:"required"`
-	ProblemID string `json:"problem_id" binding:"required"`
+	UserID        string `json:"user_id" binding:"required"`
+	ProblemID     string `json:"problem_id" binding:"required"`
+	StartToken    string `json:"start_token" binding:"required"`
+	RecallQuality *int   `json:"recall_quality" binding:"omitempty,min=0,max=5"`
+	SessionID     string `json:"session_id"`
 }
 
 type SolveTFProblemParams struct {
 	SolveProblemParams
-	BoolAnswer   *bool `json:"bool_answer" binding:"required"`
 	BoolResponse *bool `json:"bool_response" binding:"required"`
 }
 
 func (db *MongoDB) SolveTFProblem(arg SolveTFProblemParams) (TFProblemAttempt, error) {
 	attempt := tfProblemAttemptFromParams(arg)
+	response := ProblemResponse{BoolResponse: arg.BoolResponse}
+
+	problem, err := db.prepareProblemAttempt(arg.SolveProblemParams, response, &attempt.ProblemAttempt)
+	if err != nil {
+		return attempt, err
+	}
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
 	result, err := collection.InsertOne(context.Background(), attempt)
@@ -358,23 +411,7 @@ func (db *MongoDB) SolveTFProblem(arg SolveTFProblemParams) (TFProblemAttempt, e
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	attempt.ID = id
 
-	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
-	filter := bson.M{"user_id": arg.UserID, "problem_id": arg.ProblemID}
-	update := bson.M{
-		"$push": bson.M{"problem_attempts_ids": id},
-		"$setOnInsert": bson.M{
-			"user_id":     arg.UserID,
-			"problem_id":  arg.ProblemID,
-			"vote_status": NoVote,
-		},
-	}
-	options := options.Update().SetUpsert(true)
-	_, err = collection.UpdateOne(context.Background(), filter, update, options)
-	if err != nil {
-		return attempt, err
-	}
-
-	err = db.updateProblemAttempts(arg.SolveProblemParams, attempt.SolutionAccuracy)
+	err = db.recordProblemAttempt(arg.SolveProblemParams, attempt.ProblemAttempt, problem)
 
 	return attempt, err
 }
@@ -382,10 +419,9 @@ func (db *MongoDB) SolveTFProblem(arg SolveTFProblemParams) (TFProblemAttempt, e
 func tfProblemAttemptFromParams(arg SolveTFProblemParams) TFProblemAttempt {
 	return TFProblemAttempt{
 		ProblemAttempt: ProblemAttempt{
-			UserID:           arg.UserID,
-			ProblemID:        arg.ProblemID,
-			AttemptedAt:      time.Now(),
-			SolutionAccuracy: tfSolutionAccuracy(*arg.BoolAnswer, *arg.BoolResponse),
+			UserID:      arg.UserID,
+			ProblemID:   arg.ProblemID,
+			AttemptedAt: time.Now(),
 		},
 		BoolResponse: *arg.BoolResponse,
 	}
@@ -398,45 +434,281 @@ func tfSolutionAccuracy(answer bool, response bool) SolutionAccuracy {
 	return Incorrect
 }
 
-func (db *MongoDB) updateProblemAttempts(arg SolveProblemParams, solutionAccuracy SolutionAccuracy) error {
-	problem, err := db.GetProblem(arg.ProblemID)
+// responseMatches checks that the response has the shape expected by the type of the problem.
+func responseMatches(problem AnyProblem, response ProblemResponse) bool {
+	switch problem.ProblemType {
+	case TrueFalse:
+		return response.BoolResponse != nil
+	case MultipleTrueFalse:
+		return len(response.BoolResponses) == len(problem.BoolAnswers)
+	case MultipleChoice:
+		return response.ItemResponse != nil && *response.ItemResponse >= 0 && *response.ItemResponse < len(problem.Items)
+	case MultipleSelection:
+		return len(response.ItemResponses) == len(problem.CorrectItems)
+	}
+	return false
+}
+
+// gradeResponse grades the response against the answer key of the problem.
+// A response that does not match the problem is incorrect.
+func gradeResponse(problem AnyProblem, response ProblemResponse) SolutionAccuracy {
+	if !responseMatches(problem, response) {
+		return Incorrect
+	}
+
+	switch problem.ProblemType {
+	case TrueFalse:
+		return tfSolutionAccuracy(problem.BoolAnswer, *response.BoolResponse)
+	case MultipleTrueFalse:
+		return mtfSolutionAccuracy(problem.BoolAnswers, response.BoolResponses)
+	case MultipleChoice:
+		return mcSolutionAccuracy(problem.CorrectItem, *response.ItemResponse)
+	case MultipleSelection:
+		return mtfSolutionAccuracy(problem.CorrectItems, response.ItemResponses)
+	}
+	return Incorrect
+}
+
+// Users must wait this long between two attempts at the same problem. Attempts are graded
+// against the stored answer key, so this keeps the options from being brute-forced quickly.
+const attemptCooldown = 30 * time.Second
+
+// historyIndexes keep a single history per user and problem, which the attempt cooldown relies on.
+var historyIndexes = map[string]mongo.IndexModel{
+	"user_problem_histories": {
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "problem_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+}
+
+// claimAttemptCooldown starts the cooldown of the user at the problem, unless it is already running.
+// The cooldown is checked and started in a single update, so concurrent attempts cannot both pass.
+// When the cooldown is running the filter matches nothing and the upsert collides with the history.
+func (db *MongoDB) claimAttemptCooldown(userID string, problemID string, attemptedAt time.Time) error {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	filter := bson.M{
+		"user_id":        userID,
+		"problem_id":     problemID,
+		"cooldown_until": bson.M{"$not": bson.M{"$gt": attemptedAt}},
+	}
+	update := bson.M{
+		"$set":         bson.M{"cooldown_until": attemptedAt.Add(attemptCooldown)},
+		"$setOnInsert": bson.M{"vote_status": NoVote},
+	}
+	_, err := collection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.New("attempt cooldown active")
+	}
+
+	return err
+}
+
+// prepareProblemAttempt runs the checks that must pass before an attempt is stored, grades
+// the response against the answer key of the problem and returns the attempted problem.
+func (db *MongoDB) prepareProblemAttempt(arg SolveProblemParams, response ProblemResponse, attempt *ProblemAttempt) (AnyProblem, error) {
+	problem, err := db.GetVisibleProblem(arg.ProblemID, arg.UserID)
+	if err != nil {
+		return problem, err
+	}
+
+	if !responseMatches(problem, response) {
+		return problem, errors.New("response does not match the problem")
+	}
+	attempt.SolutionAccuracy = gradeResponse(problem, response)
+
+	if arg.SessionID != "" {
+		if err := db.checkListSessionAnswer(arg); err != nil {
+			return problem, err
+		}
+	}
+
+	if err := db.checkNotInRunningExam(arg.UserID, arg.ProblemID); err != nil {
+		return problem, err
+	}
+
+	if err := db.claimAttemptCooldown(arg.UserID, arg.ProblemID, attempt.AttemptedAt); err != nil {
+		return problem, err
+	}
+
+	attempt.SubjectID = problem.SubjectID
+	attempt.TopicID = problem.TopicID
+	attempt.SubtopicID = problem.SubtopicID
+
+	return problem, db.timeProblemAttempt(arg, attempt)
+}
+
+func (db *MongoDB) recordProblemAttempt(arg SolveProblemParams, attempt ProblemAttempt, problem AnyProblem) error {
+	isFirstAttempt, err := db.updateUserProblemHistory(arg, attempt, problem)
+	if err != nil {
+		return err
+	}
+
+	err = db.updateProblemAttempts(problem, attempt.SolutionAccuracy, isFirstAttempt)
+	if err != nil {
+		return err
+	}
+
+	if isFirstAttempt {
+		err = db.updateLeaderboards(attempt)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.updateStreak(attempt)
 	if err != nil {
 		return err
 	}
 
-	attempts := problem.Attempts + 1
-	correctAnswers := problem.CorrectAnswers
+	if arg.SessionID != "" {
+		err = db.recordListSessionAnswer(arg, attempt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if attempt.SolutionAccuracy == Correct {
+		err = db.markSolveLaterDone(attempt.UserID, attempt.ProblemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	db.evaluateAchievements(attempt.UserID, ProblemSolvedEvent)
+
+	return nil
+}
+
+// getUserProblemHistory returns an empty history if the user never attempted the problem.
+func (db *MongoDB) getUserProblemHistory(userID string, problemID string) (UserProblemHistory, error) {
+	var history UserProblemHistory
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	filter := bson.M{"user_id": userID, "problem_id": problemID}
+	err := collection.FindOne(context.Background(), filter).Decode(&history)
+	if err == mongo.ErrNoDocuments {
+		return history, nil
+	}
+
+	return history, err
+}
+
+// updateUserProblemHistory reports whether the attempt is the first one of the user at the problem.
+// That is decided by the history as the update found it, so of two concurrent attempts only one is first.
+func (db *MongoDB) updateUserProblemHistory(arg SolveProblemParams, attempt ProblemAttempt, problem AnyProblem) (bool, error) {
+	history, err := db.getUserProblemHistory(arg.UserID, arg.ProblemID)
+	if err != nil {
+		return false, err
+	}
+
+	quality := recallQuality(arg.RecallQuality, attempt.SolutionAccuracy)
+	schedule := nextReviewSchedule(history, quality, attempt.AttemptedAt)
+
+	set := bson.M{
+		"ease":             schedule.Ease,
+		"interval":         schedule.Interval,
+		"repetitions":      schedule.Repetitions,
+		"due_at":           schedule.DueAt,
+		"last_reviewed_at": attempt.AttemptedAt,
+
+		"last_solution_accuracy": attempt.SolutionAccuracy,
+		"subject_id":             problem.SubjectID,
+		"solved":                 history.Solved || attempt.SolutionAccuracy == Correct,
+	}
+	if len(history.ProblemAttemptsIDs) > 0 && !history.DueAt.IsZero() && !history.DueAt.After(attempt.AttemptedAt) {
+		set["last_due_review_at"] = attempt.AttemptedAt
+	}
+
+	update := bson.M{
+		"$push": bson.M{"problem_attempts_ids": attempt.ID},
+		"$set":  set,
+		"$setOnInsert": bson.M{
+			"user_id":     arg.UserID,
+			"problem_id":  arg.ProblemID,
+			"vote_status": NoVote,
+		},
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	filter := bson.M{"user_id": arg.UserID, "problem_id": arg.ProblemID}
+	findOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	var before UserProblemHistory
+	err = collection.FindOneAndUpdate(context.Background(), filter, update, findOptions).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return len(before.ProblemAttemptsIDs) == 0, nil
+}
+
+// updateProblemAttempts counts the attempt in the stats of the problem. The counters are
+// incremented and the accuracies derived from them in a single pipeline update, so
+// concurrent attempts at the same problem are all counted.
+func (db *MongoDB) updateProblemAttempts(problem AnyProblem, solutionAccuracy SolutionAccuracy, isFirstAttempt bool) error {
+	correct := 0
 	if solutionAccuracy == Correct {
-		correctAnswers++
+		correct = 1
 	}
-	accuracy := float32(correctAnswers) / float32(attempts)
 
-	objectID, err := primitive.ObjectIDFromHex(arg.ProblemID)
+	counters := bson.M{
+		"attempts":        increment("attempts", 1),
+		"correct_answers": increment("correct_answers", correct),
+	}
+	accuracies := bson.M{
+		"accuracy": ratio("correct_answers", "attempts"),
+	}
+	if isFirstAttempt {
+		counters["first_attempts"] = increment("first_attempts", 1)
+		counters["first_correct_answers"] = increment("first_correct_answers", correct)
+		accuracies["first_attempt_accuracy"] = ratio("first_correct_answers", "first_attempts")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(problem.ID)
 	if err != nil {
 		return err
 	}
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
 	filter := bson.M{"_id": objectID}
-	update := bson.M{"$set": bson.M{
-		"attempts":        attempts,
-		"correct_answers": correctAnswers,
-		"accuracy":        accuracy,
-	}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: counters}},
+		{{Key: "$set", Value: accuracies}},
+	}
 
 	_, err = collection.UpdateOne(context.Background(), filter, update)
 
 	return err
 }
 
+// increment is a pipeline expression adding the amount to the field, which may be missing.
+func increment(field string, amount int) bson.M {
+	return bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + field, 0}}, amount}}
+}
+
+// ratio is a pipeline expression dividing the fields, which is zero when the divisor is.
+func ratio(dividend string, divisor string) bson.M {
+	return bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{"$" + divisor, 0}},
+		bson.M{"$divide": bson.A{"$" + dividend, "$" + divisor}},
+		0,
+	}}
+}
+
 type SolveMTFProblemParams struct {
 	SolveProblemParams
-	BoolAnswers   []bool `json:"bool_answers" binding:"required"`
 	BoolResponses []bool `json:"bool_responses" binding:"required"`
 }
 
 func (db *MongoDB) SolveMTFProblem(arg SolveMTFProblemParams) (MTFProblemAttempt, error) {
 	attempt := mtfProblemAttemptFromParams(arg)
+	response := ProblemResponse{BoolResponses: arg.BoolResponses}
+
+	problem, err := db.prepareProblemAttempt(arg.SolveProblemParams, response, &attempt.ProblemAttempt)
+	if err != nil {
+		return attempt, err
+	}
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
 	result, err := collection.InsertOne(context.Background(), attempt)
@@ -447,23 +719,7 @@ func (db *MongoDB) SolveMTFProblem(arg SolveMTFProblemParams) (MTFProblemAttempt
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	attempt.ID = id
 
-	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
-	filter := bson.M{"user_id": arg.UserID, "problem_id": arg.ProblemID}
-	update := bson.M{
-		"$push": bson.M{"problem_attempts_ids": id},
-		"$setOnInsert": bson.M{
-			"user_id":     arg.UserID,
-			"problem_id":  arg.ProblemID,
-			"vote_status": NoVote,
-		},
-	}
-	options := options.Update().SetUpsert(true)
-	_, err = collection.UpdateOne(context.Background(), filter, update, options)
-	if err != nil {
-		return attempt, err
-	}
-
-	err = db.updateProblemAttempts(arg.SolveProblemParams, attempt.SolutionAccuracy)
+	err = db.recordProblemAttempt(arg.SolveProblemParams, attempt.ProblemAttempt, problem)
 
 	return attempt, err
 }
@@ -471,10 +727,9 @@ func (db *MongoDB) SolveMTFProblem(arg SolveMTFProblemParams) (MTFProblemAttempt
 func mtfProblemAttemptFromParams(arg SolveMTFProblemParams) MTFProblemAttempt {
 	return MTFProblemAttempt{
 		ProblemAttempt: ProblemAttempt{
-			UserID:           arg.UserID,
-			ProblemID:        arg.ProblemID,
-			AttemptedAt:      time.Now(),
-			SolutionAccuracy: mtfSolutionAccuracy(arg.BoolAnswers, arg.BoolResponses),
+			UserID:      arg.UserID,
+			ProblemID:   arg.ProblemID,
+			AttemptedAt: time.Now(),
 		},
 		BoolResponses: arg.BoolResponses,
 	}
@@ -501,12 +756,17 @@ func mtfSolutionAccuracy(answers []bool, responses []bool) SolutionAccuracy {
 
 type SolveMCProblemParams struct {
 	SolveProblemParams
-	CorrectItem  *int `json:"correct_item" binding:"required"`
 	ItemResponse *int `json:"item_response" binding:"required"`
 }
 
 func (db *MongoDB) SolveMCProblem(arg SolveMCProblemParams) (MCProblemAttempt, error) {
 	attempt := mcProblemAttemptFromParams(arg)
+	response := ProblemResponse{ItemResponse: arg.ItemResponse}
+
+	problem, err := db.prepareProblemAttempt(arg.SolveProblemParams, response, &attempt.ProblemAttempt)
+	if err != nil {
+		return attempt, err
+	}
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
 	result, err := collection.InsertOne(context.Background(), attempt)
@@ -517,23 +777,7 @@ func (db *MongoDB) SolveMCProblem(arg SolveMCProblemParams) (MCProblemAttempt, e
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	attempt.ID = id
 
-	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
-	filter := bson.M{"user_id": arg.UserID, "problem_id": arg.ProblemID}
-	update := bson.M{
-		"$push": bson.M{"problem_attempts_ids": id},
-		"$setOnInsert": bson.M{
-			"user_id":     arg.UserID,
-			"problem_id":  arg.ProblemID,
-			"vote_status": NoVote,
-		},
-	}
-	options := options.Update().SetUpsert(true)
-	_, err = collection.UpdateOne(context.Background(), filter, update, options)
-	if err != nil {
-		return attempt, err
-	}
-
-	err = db.updateProblemAttempts(arg.SolveProblemParams, attempt.SolutionAccuracy)
+	err = db.recordProblemAttempt(arg.SolveProblemParams, attempt.ProblemAttempt, problem)
 
 	return attempt, err
 }
@@ -541,10 +785,9 @@ func (db *MongoDB) SolveMCProblem(arg SolveMCProblemParams) (MCProblemAttempt, e
 func mcProblemAttemptFromParams(arg SolveMCProblemParams) MCProblemAttempt {
 	return MCProblemAttempt{
 		ProblemAttempt: ProblemAttempt{
-			UserID:           arg.UserID,
-			ProblemID:        arg.ProblemID,
-			AttemptedAt:      time.Now(),
-			SolutionAccuracy: mcSolutionAccuracy(*arg.CorrectItem, *arg.ItemResponse),
+			UserID:      arg.UserID,
+			ProblemID:   arg.ProblemID,
+			AttemptedAt: time.Now(),
 		},
 		ItemResponse: *arg.ItemResponse,
 	}
@@ -559,12 +802,17 @@ func mcSolutionAccuracy(answer int, response int) SolutionAccuracy {
 
 type SolveMSProblemParams struct {
 	SolveProblemParams
-	CorrectItems  []bool `json:"correct_items" binding:"required"`
 	ItemResponses []bool `json:"item_responses" binding:"required"`
 }
 
 func (db *MongoDB) SolveMSProblem(arg SolveMSProblemParams) (MSProblemAttempt, error) {
 	attempt := msProblemAttemptFromParams(arg)
+	response := ProblemResponse{ItemResponses: arg.ItemResponses}
+
+	problem, err := db.prepareProblemAttempt(arg.SolveProblemParams, response, &attempt.ProblemAttempt)
+	if err != nil {
+		return attempt, err
+	}
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
 	result, err := collection.InsertOne(context.Background(), attempt)
@@ -575,23 +823,7 @@ func (db *MongoDB) SolveMSProblem(arg SolveMSProblemParams) (MSProblemAttempt, e
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	attempt.ID = id
 
-	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
-	filter := bson.M{"user_id": arg.UserID, "problem_id": arg.ProblemID}
-	update := bson.M{
-		"$push": bson.M{"problem_attempts_ids": id},
-		"$setOnInsert": bson.M{
-			"user_id":     arg.UserID,
-			"problem_id":  arg.ProblemID,
-			"vote_status": NoVote,
-		},
-	}
-	options := options.Update().SetUpsert(true)
-	_, err = collection.UpdateOne(context.Background(), filter, update, options)
-	if err != nil {
-		return attempt, err
-	}
-
-	err = db.updateProblemAttempts(arg.SolveProblemParams, attempt.SolutionAccuracy)
+	err = db.recordProblemAttempt(arg.SolveProblemParams, attempt.ProblemAttempt, problem)
 
 	return attempt, err
 }
@@ -599,10 +831,9 @@ func (db *MongoDB) SolveMSProblem(arg SolveMSProblemParams) (MSProblemAttempt, e
 func msProblemAttemptFromParams(arg SolveMSProblemParams) MSProblemAttempt {
 	return MSProblemAttempt{
 		ProblemAttempt: ProblemAttempt{
-			UserID:           arg.UserID,
-			ProblemID:        arg.ProblemID,
-			AttemptedAt:      time.Now(),
-			SolutionAccuracy: mtfSolutionAccuracy(arg.CorrectItems, arg.ItemResponses),
+			UserID:      arg.UserID,
+			ProblemID:   arg.ProblemID,
+			AttemptedAt: time.Now(),
 		},
 		ItemResponses: arg.ItemResponses,
 	}
@@ -655,8 +886,18 @@ func (db *MongoDB) VoteProblem(arg VoteProblemParams) error {
 		update = bson.M{"$inc": bson.M{"downvotes": 1}}
 		_, err = collection.UpdateOne(context.Background(), filter, update)
 	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	problem, err := db.GetProblem(arg.ProblemID)
+	if err != nil {
+		return err
+	}
+
+	db.evaluateAchievements(problem.CreatorID, ProblemVotedEvent)
+
+	return nil
 }
 
 type ReportProblemParams struct {
@@ -677,8 +918,19 @@ func reportFromParams(arg ReportProblemParams) ProblemReport {
 func (db *MongoDB) CreateProblemReport(arg ReportProblemParams) (ProblemReport, error) {
 	report := reportFromParams(arg)
 
+	_, err := db.GetProblem(report.ProblemID)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return report, errors.New("problem not found")
+	}
+	if err != nil {
+		return report, err
+	}
+
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_reports")
 	result, err := collection.InsertOne(context.Background(), report)
+	if err != nil {
+		return report, err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	report.ID = id
@@ -2,15 +2,38 @@ package db
 
 import (
 	"context"
+	"errors"
 	"os"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProblemAttemptDatabase interface {
 	ListUserAttempts(id string, pagination PaginationParams) ([]ProblemAttemptTableRow, error)
+
+	ExportUserAttempts(userID string, write func(AttemptExportRow) error) error
+
+	StartProblemAttempt(arg StartProblemAttemptParams, problemID string) (AttemptStart, error)
+	GetProblemAnalytics(problemID string, userID string) (ProblemAnalytics, error)
+}
+
+// Attempts answered faster than this are flagged as implausibly fast.
+const minimumPlausibleDuration = 3 * time.Second
+
+// Start tokens expire after this long, so problems opened and never solved leave nothing behind.
+// Solving with an expired token fails, and the problem has to be started again.
+const attemptStartLifetime = 24 * time.Hour
+
+// attemptStartIndexes let the database delete expired start tokens.
+var attemptStartIndexes = map[string]mongo.IndexModel{
+	"attempt_starts": {
+		Keys:    bson.D{{Key: "started_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(attemptStartLifetime.Seconds())),
+	},
 }
 
 type ProblemAttemptTableRow struct {
@@ -64,20 +87,334 @@ func (db *MongoDB) attemptTableRowFromAttempt(attempt ProblemAttempt) (ProblemAt
 
 	id := attempt.ProblemID
 	problem, err := db.GetProblem(id)
-	if err != nil {
+	if err != nil && err != mongo.ErrNoDocuments && err != primitive.ErrInvalidHex {
 		return attemptTableRow, err
 	}
 
-	subject, err := db.GetSubject(problem.SubjectID)
+	// attempts of deleted problems are kept, so they are shown like problems the user cannot see
+	if err != nil || !canViewProblem(problem.Problem, attempt.UserID) {
+		return ProblemAttemptTableRow{
+			ProblemID:        id,
+			AttemptedAt:      attempt.AttemptedAt,
+			SolutionAccuracy: attempt.SolutionAccuracy,
+		}, nil
+	}
+
+	subject, err := db.labelName("subjects", problem.SubjectID, make(map[string]string))
 	if err != nil {
 		return attemptTableRow, err
 	}
 
 	return ProblemAttemptTableRow{
-		Subject:          subject.Name,
+		Subject:          subject,
 		Statement:        problem.Statement,
 		ProblemID:        id,
 		AttemptedAt:      attempt.AttemptedAt,
 		SolutionAccuracy: attempt.SolutionAccuracy,
 	}, nil
 }
+
+type AttemptExportRow struct {
+	AttemptID        string      `json:"attempt_id"`
+	AttemptedAt      time.Time   `json:"attempted_at"`
+	ProblemID        string      `json:"problem_id"`
+	ProblemType      string      `json:"problem_type"`
+	Statement        string      `json:"statement"`
+	Subject          string      `json:"subject"`
+	Topic            string      `json:"topic"`
+	Subtopic         string      `json:"subtopic"`
+	Response         interface{} `json:"response"`
+	SolutionAccuracy string      `json:"solution_accuracy"`
+	DurationMs       int64       `json:"duration_ms"`
+}
+
+// ExportUserAttempts calls write with every attempt of the user, the oldest first.
+// Attempts are read through a cursor, so the history is never fully loaded in memory,
+// and only the few fields of each problem that the rows need are kept.
+// Deleted problems and labels are exported with empty names, as are problems the user can no longer see.
+func (db *MongoDB) ExportUserAttempts(userID string, write func(AttemptExportRow) error) error {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
+	findOptions := options.Find().SetSort(bson.D{{Key: "attempted_at", Value: 1}})
+	cursor, err := collection.Find(context.Background(), bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	problems := make(map[string]exportedProblem)
+	labelNames := make(map[string]string)
+
+	for cursor.Next(context.Background()) {
+		var attempt AnyProblemAttempt
+		if err := cursor.Decode(&attempt); err != nil {
+			return err
+		}
+
+		problem, ok := problems[attempt.ProblemID]
+		if !ok {
+			problem, err = db.exportedProblem(attempt.ProblemID, userID)
+			if err != nil {
+				return err
+			}
+			problems[attempt.ProblemID] = problem
+		}
+
+		row := AttemptExportRow{
+			AttemptID:        attempt.ID,
+			AttemptedAt:      attempt.AttemptedAt,
+			ProblemID:        attempt.ProblemID,
+			ProblemType:      problem.ProblemType.String(),
+			Statement:        problem.Statement,
+			Response:         attemptResponse(attempt, problem.ProblemType),
+			SolutionAccuracy: attempt.SolutionAccuracy.String(),
+			DurationMs:       attempt.DurationMs,
+		}
+		if problem.ID == "" {
+			row.ProblemType = ""
+		}
+
+		row.Subject, err = db.labelName("subjects", problem.SubjectID, labelNames)
+		if err != nil {
+			return err
+		}
+		row.Topic, err = db.labelName("topics", problem.TopicID, labelNames)
+		if err != nil {
+			return err
+		}
+		row.Subtopic, err = db.labelName("subtopics", problem.SubtopicID, labelNames)
+		if err != nil {
+			return err
+		}
+
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// exportedProblem is the part of a problem shown in the exported attempts.
+type exportedProblem struct {
+	ID          string      `bson:"_id"`
+	ProblemType ProblemType `bson:"problem_type"`
+	Statement   string      `bson:"statement"`
+	SubjectID   string      `bson:"subject_id"`
+	TopicID     string      `bson:"topic_id"`
+	SubtopicID  string      `bson:"subtopic_id"`
+	Visibility  Visibility  `bson:"visibility"`
+	CreatorID   string      `bson:"creator_id"`
+}
+
+// exportedProblem returns the problem as shown in the exported attempts,
+// which is empty when the problem was deleted or the user can no longer see it.
+func (db *MongoDB) exportedProblem(problemID string, userID string) (exportedProblem, error) {
+	var problem exportedProblem
+
+	objectID, err := primitive.ObjectIDFromHex(problemID)
+	if err != nil {
+		return problem, nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
+	findOptions := options.FindOne().SetProjection(bson.M{
+		"problem_type": 1,
+		"statement":    1,
+		"subject_id":   1,
+		"topic_id":     1,
+		"subtopic_id":  1,
+		"visibility":   1,
+		"creator_id":   1,
+	})
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}, findOptions).Decode(&problem)
+	if err == mongo.ErrNoDocuments {
+		return exportedProblem{}, nil
+	}
+	if err != nil {
+		return problem, err
+	}
+
+	if !canViewProblem(Problem{Visibility: problem.Visibility, CreatorID: problem.CreatorID}, userID) {
+		return exportedProblem{}, nil
+	}
+
+	return problem, nil
+}
+
+func attemptResponse(attempt AnyProblemAttempt, problemType ProblemType) interface{} {
+	switch problemType {
+	case TrueFalse:
+		return attempt.BoolResponse
+	case MultipleTrueFalse:
+		return attempt.BoolResponses
+	case MultipleChoice:
+		return attempt.ItemResponse
+	case MultipleSelection:
+		return attempt.ItemResponses
+	}
+	return nil
+}
+
+// labelName returns the name of a subject, topic or subtopic, caching it in names.
+func (db *MongoDB) labelName(collectionName string, id string, names map[string]string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+	if name, ok := names[id]; ok {
+		return name, nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		names[id] = ""
+		return "", nil
+	}
+
+	var label struct {
+		Name string `bson:"name"`
+	}
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection(collectionName)
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&label)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+
+	names[id] = label.Name
+	return label.Name, nil
+}
+
+type StartProblemAttemptParams struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// StartProblemAttempt issues the token that the solve call must echo back.
+// The time between the start and the solve is stored as the attempt duration.
+func (db *MongoDB) StartProblemAttempt(arg StartProblemAttemptParams, problemID string) (AttemptStart, error) {
+	start := AttemptStart{
+		UserID:    arg.UserID,
+		ProblemID: problemID,
+		StartedAt: time.Now(),
+	}
+
+	if _, err := db.GetVisibleProblem(problemID, arg.UserID); err != nil {
+		return start, err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("attempt_starts")
+	result, err := collection.InsertOne(context.Background(), start)
+	if err != nil {
+		return start, err
+	}
+
+	start.ID = result.InsertedID.(primitive.ObjectID).Hex()
+
+	return start, nil
+}
+
+// timeProblemAttempt consumes the start token of the attempt and sets its duration.
+func (db *MongoDB) timeProblemAttempt(arg SolveProblemParams, attempt *ProblemAttempt) error {
+	objectID, err := primitive.ObjectIDFromHex(arg.StartToken)
+	if err != nil {
+		return errors.New("attempt start token not found")
+	}
+
+	var start AttemptStart
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("attempt_starts")
+	filter := bson.M{"_id": objectID, "user_id": arg.UserID, "problem_id": arg.ProblemID}
+	err = collection.FindOneAndDelete(context.Background(), filter).Decode(&start)
+	if err == mongo.ErrNoDocuments {
+		return errors.New("attempt start token not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	duration := attempt.AttemptedAt.Sub(start.StartedAt)
+	// the database only deletes expired tokens once a minute
+	if duration > attemptStartLifetime {
+		return errors.New("attempt start token expired")
+	}
+	attempt.DurationMs = duration.Milliseconds()
+	attempt.TooFast = duration < minimumPlausibleDuration
+
+	return nil
+}
+
+type ProblemAnalytics struct {
+	ProblemID         string  `json:"problem_id"`
+	Attempts          int     `json:"attempts"`
+	CorrectAnswers    int     `json:"correct_answers"`
+	Accuracy          float64 `json:"accuracy"`
+	TimedAttempts     int64   `json:"timed_attempts"`
+	TooFastAttempts   int64   `json:"too_fast_attempts"`
+	MedianSolveTimeMs int64   `json:"median_solve_time_ms"`
+}
+
+// GetProblemAnalytics returns the stats of a problem.
+// The median solve time ignores attempts flagged as too fast.
+func (db *MongoDB) GetProblemAnalytics(problemID string, userID string) (ProblemAnalytics, error) {
+	analytics := ProblemAnalytics{ProblemID: problemID}
+
+	problem, err := db.GetVisibleProblem(problemID, userID)
+	if err != nil {
+		return analytics, err
+	}
+
+	analytics.Attempts = problem.Attempts
+	analytics.CorrectAnswers = problem.CorrectAnswers
+	analytics.Accuracy = problem.Accuracy
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
+	filter := bson.M{"problem_id": problemID, "too_fast": true}
+	analytics.TooFastAttempts, err = collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return analytics, err
+	}
+
+	filter = bson.M{"problem_id": problemID, "too_fast": false, "duration_ms": bson.M{"$gt": 0}}
+	analytics.TimedAttempts, err = collection.CountDocuments(context.Background(), filter)
+	if err != nil || analytics.TimedAttempts == 0 {
+		return analytics, err
+	}
+
+	analytics.MedianSolveTimeMs, err = db.medianSolveTime(filter, analytics.TimedAttempts)
+
+	return analytics, err
+}
+
+// medianPositions returns how many of the sorted values come before the middle ones and how many
+// middle values there are, which is one for an odd count and two for an even count.
+func medianPositions(count int64) (skip int64, middleValues int64) {
+	return (count - 1) / 2, 2 - count%2
+}
+
+func (db *MongoDB) medianSolveTime(filter bson.M, count int64) (int64, error) {
+	skip, middleValues := medianPositions(count)
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "duration_ms", Value: 1}})
+	findOptions.SetSkip(skip)
+	findOptions.SetLimit(middleValues)
+	findOptions.SetProjection(bson.M{"duration_ms": 1})
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return 0, err
+	}
+
+	var attempts []ProblemAttempt
+	if err := cursor.All(context.Background(), &attempts); err != nil {
+		return 0, err
+	}
+	if len(attempts) == 0 {
+		return 0, nil
+	}
+
+	var total int64
+	for _, attempt := range attempts {
+		total += attempt.DurationMs
+	}
+
+	return total / int64(len(attempts)), nil
+}
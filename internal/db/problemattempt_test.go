@@ -0,0 +1,26 @@
+package db
+
+import "testing"
+
+func TestMedianPositions(t *testing.T) {
+	tests := []struct {
+		count        int64
+		skip         int64
+		middleValues int64
+	}{
+		{1, 0, 1},
+		{2, 0, 2},
+		{3, 1, 1},
+		{4, 1, 2},
+		{7, 3, 1},
+		{10, 4, 2},
+	}
+
+	for _, test := range tests {
+		skip, middleValues := medianPositions(test.count)
+		if skip != test.skip || middleValues != test.middleValues {
+			t.Errorf("count %v: expected %v and %v, got %v and %v",
+				test.count, test.skip, test.middleValues, skip, middleValues)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ProblemEditSuggestionDatabase interface {
@@ -43,8 +44,25 @@ func suggestionFromParams(arg CreateProblemEditSuggestionParams) ProblemEditSugg
 func (db *MongoDB) CreateProblemEditSuggestion(arg CreateProblemEditSuggestionParams) (ProblemEditSuggestion, error) {
 	suggestion := suggestionFromParams(arg)
 
+	problem, err := db.GetProblem(suggestion.ProblemID)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return suggestion, errors.New("problem not found")
+	}
+	if err != nil {
+		return suggestion, err
+	}
+
+	// the suggested labels are checked along with the labels they do not change
+	edited := editedProblem(problem, suggestion)
+	if err := db.checkLabels(edited.SubjectID, edited.TopicID, edited.SubtopicID); err != nil {
+		return suggestion, err
+	}
+
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_edit_suggestions")
 	result, err := collection.InsertOne(context.Background(), suggestion)
+	if err != nil {
+		return suggestion, err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	suggestion.ID = id
@@ -142,7 +160,27 @@ func (db *MongoDB) AcceptProblemEditSuggestion(id string) (AnyProblem, error) {
 		return problem, err
 	}
 
-	return problem, nil
+	err = db.creditAcceptedSuggestion(suggestion.CreatorUsername)
+
+	return problem, err
+}
+
+func (db *MongoDB) creditAcceptedSuggestion(username string) error {
+	var user User
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter := bson.M{"username": username}
+	update := bson.M{"$inc": bson.M{"accepted_suggestions": 1}}
+	err := collection.FindOneAndUpdate(context.Background(), filter, update).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	db.evaluateAchievements(user.ID, SuggestionAcceptedEvent)
+
+	return nil
 }
 
 func editedProblem(problem AnyProblem, suggestion ProblemEditSuggestion) AnyProblem {
@@ -8,30 +8,72 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProblemListDatabase interface {
 	CreateProblemList(arg CreateProblemListParams) (ProblemList, error)
 	GetProblemList(id string) (ProblemList, error)
+	GetVisibleProblemList(id string, userID string) (ProblemList, error)
+	GetHydratedProblemList(id string, userID string) (HydratedProblemList, error)
 	ListProblemLists(arg ListProblemListsParams) ([]ProblemList, error)
 	UpdateProblemList(arg UpdateProblemListParams, id string) error
 	DeleteProblemList(id string) error
+
+	AddProblemToList(arg AddListProblemParams, listID string) (ProblemList, error)
+	RemoveProblemFromList(listID string, problemID string) (ProblemList, error)
+	MoveProblemInList(arg MoveListProblemParams, listID string, problemID string) (ProblemList, error)
+	FreezeProblemList(id string) (ProblemList, error)
+	ForkProblemList(arg ForkProblemListParams, id string) (ProblemList, error)
+
+	VoteProblemList(arg VoteProblemListParams) error
+	GetProblemListVote(listID string, userID string) (VoteStatus, error)
+
+	CreateListReport(arg ReportProblemListParams) (ListReport, error)
+	ListReportedProblemLists(pagination PaginationParams) ([]ReportedProblemList, error)
 }
 
 type CreateProblemListParams struct {
-	CreatorID   string   `json:"creator_id" binding:"required"`
-	ProblemIDs  []string `json:"problem_ids" binding:"required"`
-	Description string   `json:"description" binding:"required"`
-	Language    string   `json:"language" binding:"required,language"`
+	CreatorID        string     `json:"creator_id" binding:"required"`
+	ProblemIDs       []string   `json:"problem_ids" binding:"required_without=Query,excluded_with=Query"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description" binding:"required"`
+	SubjectID        string     `json:"subject_id"`
+	LevelOfEducation string     `json:"level_of_education" binding:"level_of_education"`
+	Language         string     `json:"language" binding:"required,language"`
+	Visibility       Visibility `json:"visibility" binding:"omitempty,visibility"`
+
+	// Query makes the list dynamic, in place of ProblemIDs
+	Query *ListQuery `json:"query"`
 }
 
 func listFromCreateParams(arg CreateProblemListParams) ProblemList {
+	visibility := arg.Visibility
+	if visibility == "" {
+		visibility = Public
+	}
+
+	kind := StaticList
+	problemIDs := arg.ProblemIDs
+	if arg.Query != nil {
+		kind = DynamicList
+		problemIDs = []string{}
+	}
+
 	return ProblemList{
-		CreatorID:   arg.CreatorID,
-		ProblemIDs:  arg.ProblemIDs,
-		Description: arg.Description,
-		CreatedAt:   time.Now(),
+		CreatorID:        arg.CreatorID,
+		Kind:             kind,
+		Visibility:       visibility,
+		ProblemIDs:       problemIDs,
+		Query:            arg.Query,
+		Collaborators:    []ListCollaborator{},
+		Title:            arg.Title,
+		Description:      arg.Description,
+		SubjectID:        arg.SubjectID,
+		LevelOfEducation: arg.LevelOfEducation,
+		Language:         arg.Language,
+		CreatedAt:        time.Now(),
 
 		Upvotes:   0,
 		Downvotes: 0,
@@ -41,15 +83,81 @@ func listFromCreateParams(arg CreateProblemListParams) ProblemList {
 func (db *MongoDB) CreateProblemList(arg CreateProblemListParams) (ProblemList, error) {
 	problemList := listFromCreateParams(arg)
 
+	if err := db.checkListReferences(problemList.SubjectID, problemList.ProblemIDs); err != nil {
+		return problemList, err
+	}
+
+	return db.insertProblemList(problemList)
+}
+
+// checkListReferences verifies that the subject and problems of a list exist.
+func (db *MongoDB) checkListReferences(subjectID string, problemIDs []string) error {
+	if err := db.checkLabels(subjectID, "", ""); err != nil {
+		return err
+	}
+
+	return db.checkProblemIDs(problemIDs)
+}
+
+func (db *MongoDB) insertProblemList(problemList ProblemList) (ProblemList, error) {
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
 	result, err := collection.InsertOne(context.Background(), problemList)
+	if err != nil {
+		return problemList, err
+	}
 
 	id := result.InsertedID.(primitive.ObjectID).Hex()
 	problemList.ID = id
 
+	err = db.addCreatedContent(problemList.CreatorID, "created_lists", id)
+	if err != nil {
+		return problemList, err
+	}
+
+	db.evaluateAchievements(problemList.CreatorID, ListCreatedEvent)
+
+	err = db.resolveDynamicList(&problemList)
 	return problemList, err
 }
 
+// maxDynamicListSize is the most problems a dynamic list can hold
+const maxDynamicListSize = 200
+
+// resolveDynamicList fills the problems of a dynamic list by running its query.
+// Static lists are left untouched.
+func (db *MongoDB) resolveDynamicList(problemList *ProblemList) error {
+	if problemList.Kind != DynamicList || problemList.Query == nil {
+		return nil
+	}
+
+	query := problemList.Query.ListProblemsParams
+	query.PaginationParams = PaginationParams{Limit: maxDynamicListSize}
+	if problemList.Query.MaxSize > 0 && problemList.Query.MaxSize < maxDynamicListSize {
+		query.Limit = int32(problemList.Query.MaxSize)
+	}
+
+	findOptions := optionsFromParams(query)
+	findOptions.SetProjection(bson.M{"_id": 1})
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
+	cursor, err := collection.Find(context.Background(), filterFromParams(query), findOptions)
+	if err != nil {
+		return err
+	}
+
+	var problems []Problem
+	if err := cursor.All(context.Background(), &problems); err != nil {
+		return err
+	}
+
+	problemList.ProblemIDs = make([]string, 0, len(problems))
+	for _, problem := range problems {
+		problemList.ProblemIDs = append(problemList.ProblemIDs, problem.ID)
+	}
+
+	return nil
+}
+
 func (db *MongoDB) GetProblemList(id string) (ProblemList, error) {
 	var problemList ProblemList
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -60,21 +168,138 @@ func (db *MongoDB) GetProblemList(id string) (ProblemList, error) {
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
 	filter := bson.D{{Key: "_id", Value: objectID}}
 	err = collection.FindOne(context.Background(), filter).Decode(&problemList)
+	if err != nil {
+		return problemList, err
+	}
+
+	err = db.resolveDynamicList(&problemList)
 	return problemList, err
 }
 
+// HydratedProblemList is a list with its problems embedded in order, without their answer keys.
+// Completion is the percentage of the problems solved by the user.
+type HydratedProblemList struct {
+	ProblemList
+	Problems   []ListProblem `json:"problems"`
+	Completion float64       `json:"completion"`
+}
+
+type ListProblem struct {
+	StrippedProblem
+	Attempted bool `json:"attempted"`
+	Solved    bool `json:"solved"`
+}
+
+// GetHydratedProblemList returns the list with its problems, annotated with the state of the user.
+// Without a user, every problem is reported as unsolved. Problems the user cannot see are left out.
+func (db *MongoDB) GetHydratedProblemList(id string, userID string) (HydratedProblemList, error) {
+	var hydrated HydratedProblemList
+
+	problemList, err := db.GetVisibleProblemList(id, userID)
+	if err != nil {
+		return hydrated, err
+	}
+	hydrated.ProblemList = problemList
+	hydrated.Problems = []ListProblem{}
+
+	var problemIDs []primitive.ObjectID
+	for _, problemID := range problemList.ProblemIDs {
+		if objectID, err := primitive.ObjectIDFromHex(problemID); err == nil {
+			problemIDs = append(problemIDs, objectID)
+		}
+	}
+
+	if len(problemIDs) == 0 {
+		return hydrated, nil
+	}
+
+	problems, err := db.problemsByID(problemIDs, "", userID)
+	if err != nil {
+		return hydrated, err
+	}
+
+	histories, err := db.userProblemHistories(userID, problemList.ProblemIDs)
+	if err != nil {
+		return hydrated, err
+	}
+
+	solved := 0
+	for _, problemID := range problemList.ProblemIDs {
+		problem, ok := problems[problemID]
+		if !ok {
+			continue
+		}
+
+		// histories are also created by votes and attempt cooldowns, before any attempt is stored
+		history := histories[problemID]
+		attempted := len(history.ProblemAttemptsIDs) > 0
+		listProblem := ListProblem{
+			StrippedProblem: strippedProblem(problem),
+			Attempted:       attempted,
+			Solved:          attempted && (history.Solved || history.LastSolutionAccuracy == Correct),
+		}
+		if listProblem.Solved {
+			solved++
+		}
+
+		hydrated.Problems = append(hydrated.Problems, listProblem)
+	}
+
+	if len(hydrated.Problems) > 0 {
+		hydrated.Completion = 100 * float64(solved) / float64(len(hydrated.Problems))
+	}
+
+	return hydrated, nil
+}
+
+// userProblemHistories returns the histories of the user for the given problems, keyed by problem id.
+func (db *MongoDB) userProblemHistories(userID string, problemIDs []string) (map[string]UserProblemHistory, error) {
+	histories := make(map[string]UserProblemHistory)
+	if userID == "" {
+		return histories, nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	filter := bson.M{"user_id": userID, "problem_id": bson.M{"$in": problemIDs}}
+	cursor, err := collection.Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var history UserProblemHistory
+		if err := cursor.Decode(&history); err != nil {
+			return nil, err
+		}
+		histories[history.ProblemID] = history
+	}
+
+	return histories, cursor.Err()
+}
+
 type ListProblemListsParams struct {
-	Limit int32 `form:"limit"`
-	Skip  int32 `form:"skip"`
+	PaginationParams
+
+	OrderBy    string `form:"order_by" binding:"omitempty,field_to_order_lists"`
+	Descending *bool  `form:"descending"`
+
+	CreatorIDFilter        string `form:"creator_id"`
+	SubjectFilter          string `form:"subject_id"`
+	LevelOfEducationFilter string `form:"level_of_education" binding:"level_of_education"`
+	LanguageFilter         string `form:"language" binding:"language"`
 }
 
+// ListProblemLists returns a list of problem lists.
+// The returned list is ordered by the field specified in the `order_by` parameter.
+// order_by can be one of the following values: "created_at", "upvotes", "fork_count"
+// The Filter can be empty. If a filter is empty, it is ignored.
 func (db *MongoDB) ListProblemLists(arg ListProblemListsParams) ([]ProblemList, error) {
-	findOptions := options.Find()
-	findOptions.SetLimit(int64(arg.Limit))
-	findOptions.SetSkip(int64(arg.Skip))
+	filter := listFilterFromParams(arg)
+	findOptions := listOptionsFromParams(arg)
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
-	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +312,9 @@ func (db *MongoDB) ListProblemLists(arg ListProblemListsParams) ([]ProblemList,
 		if err != nil {
 			return nil, err
 		}
+		if err := db.resolveDynamicList(&list); err != nil {
+			return nil, err
+		}
 		problemLists = append(problemLists, list)
 	}
 
@@ -97,47 +325,520 @@ func (db *MongoDB) ListProblemLists(arg ListProblemListsParams) ([]ProblemList,
 	return problemLists, err
 }
 
+func listFilterFromParams(arg ListProblemListsParams) bson.M {
+	filter := bson.M{"visibility": listedVisibility()}
+
+	if arg.CreatorIDFilter != "" {
+		filter["creator_id"] = arg.CreatorIDFilter
+	}
+	if arg.SubjectFilter != "" {
+		filter["subject_id"] = arg.SubjectFilter
+	}
+	if arg.LevelOfEducationFilter != "" {
+		filter["level_of_education"] = arg.LevelOfEducationFilter
+	}
+	if arg.LanguageFilter != "" {
+		filter["language"] = arg.LanguageFilter
+	}
+
+	return filter
+}
+
+func listOptionsFromParams(arg ListProblemListsParams) *options.FindOptions {
+	findOptions := options.Find()
+	findOptions.SetLimit(int64(arg.Limit))
+	findOptions.SetSkip(int64(arg.Skip))
+
+	if arg.OrderBy == "" {
+		arg.OrderBy = "created_at"
+	}
+	if arg.Descending == nil {
+		descending := true
+		arg.Descending = &descending
+	}
+
+	var sort bson.D
+	if *arg.Descending {
+		sort = bson.D{{Key: arg.OrderBy, Value: -1}}
+	} else {
+		sort = bson.D{{Key: arg.OrderBy, Value: 1}}
+	}
+	findOptions.SetSort(sort)
+
+	return findOptions
+}
+
+// UpdateProblemListParams only changes the fields that are present in the request
 type UpdateProblemListParams struct {
-	ProblemIDs  []string `json:"problem_ids"`
-	Description string   `json:"description"`
-	Language    string   `json:"language" binding:"language"`
+	ProblemIDs       []string    `json:"problem_ids"`
+	Title            *string     `json:"title"`
+	Description      *string     `json:"description"`
+	SubjectID        *string     `json:"subject_id"`
+	LevelOfEducation *string     `json:"level_of_education" binding:"omitempty,level_of_education"`
+	Language         *string     `json:"language" binding:"omitempty,language"`
+	Visibility       *Visibility `json:"visibility" binding:"omitempty,visibility"`
+
+	// ProblemIDs can only be changed on static lists and Query only on dynamic lists
+	Query *ListQuery `json:"query" binding:"excluded_with=ProblemIDs"`
+}
+
+func listUpdateFromParams(arg UpdateProblemListParams) bson.M {
+	fields := bson.M{}
+	if arg.ProblemIDs != nil {
+		fields["problem_ids"] = arg.ProblemIDs
+	}
+	if arg.Title != nil {
+		fields["title"] = *arg.Title
+	}
+	if arg.Description != nil {
+		fields["description"] = *arg.Description
+	}
+	if arg.SubjectID != nil {
+		fields["subject_id"] = *arg.SubjectID
+	}
+	if arg.LevelOfEducation != nil {
+		fields["level_of_education"] = *arg.LevelOfEducation
+	}
+	if arg.Language != nil {
+		fields["language"] = *arg.Language
+	}
+	if arg.Visibility != nil {
+		fields["visibility"] = *arg.Visibility
+	}
+	if arg.Query != nil {
+		fields["query"] = arg.Query
+	}
+	return fields
 }
 
 func (db *MongoDB) UpdateProblemList(arg UpdateProblemListParams, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
+		return errors.New("problem list not found")
+	}
+
+	fields := listUpdateFromParams(arg)
+	if len(fields) == 0 {
+		return errors.New("no fields to update")
+	}
+
+	subjectID := ""
+	if arg.SubjectID != nil {
+		subjectID = *arg.SubjectID
+	}
+	if err := db.checkListReferences(subjectID, arg.ProblemIDs); err != nil {
 		return err
 	}
 
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
 	filter := bson.M{"_id": objectID}
-	update := bson.M{"$set": bson.M{
-		"problem_ids": arg.ProblemIDs,
-		"description": arg.Description,
-		"language":    arg.Language,
-	}}
-	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if arg.ProblemIDs != nil {
+		filter["kind"] = bson.M{"$ne": DynamicList}
+	}
+	if arg.Query != nil {
+		filter["kind"] = DynamicList
+	}
+	result, err := collection.UpdateOne(context.Background(), filter, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
 
 	if result.MatchedCount == 0 {
-		return errors.New("list not found")
+		return db.listEditFailure(objectID, "problem list is not dynamic")
 	}
 
-	return err
+	return nil
+}
+
+type AddListProblemParams struct {
+	ProblemID string `json:"problem_id" binding:"required"`
+	// Position is where the problem is inserted, the problem is appended when it is missing
+	Position *int `json:"position" binding:"omitempty,min=0"`
+}
+
+// AddProblemToList inserts the problem in the list unless it is already there.
+func (db *MongoDB) AddProblemToList(arg AddListProblemParams, listID string) (ProblemList, error) {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return ProblemList{}, errors.New("problem list not found")
+	}
+
+	_, err = db.GetProblem(arg.ProblemID)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return ProblemList{}, errors.New("problem not found")
+	}
+	if err != nil {
+		return ProblemList{}, err
+	}
+
+	push := bson.M{"$each": []string{arg.ProblemID}}
+	if arg.Position != nil {
+		push["$position"] = *arg.Position
+	}
+
+	filter := bson.M{"_id": objectID, "kind": bson.M{"$ne": DynamicList}, "problem_ids": bson.M{"$ne": arg.ProblemID}}
+	update := bson.M{"$push": bson.M{"problem_ids": push}}
+
+	return db.editListProblems(objectID, filter, update, "problem already in list")
+}
+
+// RemoveProblemFromList removes the problem from the list, keeping the order of the others.
+func (db *MongoDB) RemoveProblemFromList(listID string, problemID string) (ProblemList, error) {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return ProblemList{}, errors.New("problem list not found")
+	}
+
+	filter := bson.M{"_id": objectID, "kind": bson.M{"$ne": DynamicList}, "problem_ids": problemID}
+	update := bson.M{"$pull": bson.M{"problem_ids": problemID}}
+
+	return db.editListProblems(objectID, filter, update, "problem not in list")
+}
+
+type MoveListProblemParams struct {
+	Position *int `json:"position" binding:"required,min=0"`
+}
+
+// MoveProblemInList moves the problem to the given position, shifting the problems in between.
+// The move is a single pipeline update, so concurrent edits to other problems are kept.
+func (db *MongoDB) MoveProblemInList(arg MoveListProblemParams, listID string, problemID string) (ProblemList, error) {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return ProblemList{}, errors.New("problem list not found")
+	}
+
+	others := bson.M{"$filter": bson.M{
+		"input": "$problem_ids",
+		"cond":  bson.M{"$ne": bson.A{"$$this", problemID}},
+	}}
+	moved := bson.M{"$concatArrays": bson.A{
+		bson.M{"$slice": bson.A{"$$others", *arg.Position}},
+		bson.A{problemID},
+		bson.M{"$slice": bson.A{"$$others", *arg.Position, bson.M{"$max": bson.A{1, bson.M{"$size": "$$others"}}}}},
+	}}
+
+	filter := bson.M{"_id": objectID, "kind": bson.M{"$ne": DynamicList}, "problem_ids": problemID}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{"problem_ids": bson.M{"$let": bson.M{
+		"vars": bson.M{"others": others},
+		"in":   moved,
+	}}}}}}
+
+	return db.editListProblems(objectID, filter, update, "problem not in list")
+}
+
+// editListProblems applies the update to the list and returns the updated list.
+// When the filter does not match, the list is missing, dynamic, or fails the precondition.
+func (db *MongoDB) editListProblems(objectID primitive.ObjectID, filter bson.M, update interface{}, preconditionError string) (ProblemList, error) {
+	var problemList ProblemList
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := collection.FindOneAndUpdate(context.Background(), filter, update, updateOptions).Decode(&problemList)
+	if err != mongo.ErrNoDocuments {
+		return problemList, err
+	}
+
+	return problemList, db.listEditFailure(objectID, preconditionError)
+}
+
+// listEditFailure explains why an edit did not match the list.
+func (db *MongoDB) listEditFailure(objectID primitive.ObjectID, preconditionError string) error {
+	var problemList ProblemList
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	findOptions := options.FindOne().SetProjection(bson.M{"kind": 1})
+	err := collection.FindOne(context.Background(), bson.M{"_id": objectID}, findOptions).Decode(&problemList)
+	if err == mongo.ErrNoDocuments {
+		return errors.New("problem list not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if problemList.Kind == DynamicList {
+		return errors.New("dynamic list cannot be edited")
+	}
+
+	return errors.New(preconditionError)
+}
+
+// FreezeProblemList turns a dynamic list into a static list with the problems its query returns now.
+func (db *MongoDB) FreezeProblemList(id string) (ProblemList, error) {
+	problemList, err := db.GetProblemList(id)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return problemList, errors.New("problem list not found")
+	}
+	if err != nil {
+		return problemList, err
+	}
+
+	if problemList.Kind != DynamicList {
+		return problemList, errors.New("problem list is not dynamic")
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return problemList, err
+	}
+
+	filter := bson.M{"_id": objectID, "kind": DynamicList}
+	update := bson.M{
+		"$set":   bson.M{"kind": StaticList, "problem_ids": problemList.ProblemIDs},
+		"$unset": bson.M{"query": ""},
+	}
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = collection.FindOneAndUpdate(context.Background(), filter, update, updateOptions).Decode(&problemList)
+	if err == mongo.ErrNoDocuments {
+		return problemList, errors.New("problem list is not dynamic")
+	}
+
+	return problemList, err
+}
+
+type ForkProblemListParams struct {
+	UserID string `json:"user_id" binding:"required"`
+	// Title defaults to the title of the original list
+	Title string `json:"title"`
+}
+
+// ForkProblemList copies the list into a new static list owned by the user.
+// The fork holds the problems of the original the user can see, in the same order,
+// and keeps its visibility. Collaborators and exam settings are not copied.
+func (db *MongoDB) ForkProblemList(arg ForkProblemListParams, id string) (ProblemList, error) {
+	original, err := db.GetVisibleProblemList(id, arg.UserID)
+	if err != nil {
+		return ProblemList{}, err
+	}
+
+	problemIDs, err := db.visibleProblemIDs(original.ProblemIDs, arg.UserID)
+	if err != nil {
+		return ProblemList{}, err
+	}
+
+	title := arg.Title
+	if title == "" {
+		title = original.Title
+	}
+
+	fork := listFromCreateParams(CreateProblemListParams{
+		CreatorID:        arg.UserID,
+		ProblemIDs:       problemIDs,
+		Title:            title,
+		Description:      original.Description,
+		SubjectID:        original.SubjectID,
+		LevelOfEducation: original.LevelOfEducation,
+		Language:         original.Language,
+		Visibility:       original.Visibility,
+	})
+	fork.ForkedFrom = original.ID
+
+	fork, err = db.insertProblemList(fork)
+	if err != nil {
+		return fork, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(original.ID)
+	if err != nil {
+		return fork, err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{"$inc": bson.M{"fork_count": 1}})
+
+	return fork, err
 }
 
 func (db *MongoDB) DeleteProblemList(id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return err
+		return errors.New("problem list not found")
 	}
 
+	var problemList ProblemList
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
 	filter := bson.D{{Key: "_id", Value: objectID}}
-	result, err := collection.DeleteOne(context.Background(), filter)
+	err = collection.FindOneAndDelete(context.Background(), filter).Decode(&problemList)
+	if err == mongo.ErrNoDocuments {
+		return errors.New("problem list not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := db.deleteListReferences(id); err != nil {
+		return err
+	}
+
+	return db.removeCreatedContent(problemList.CreatorID, "created_lists", id)
+}
+
+type VoteProblemListParams struct {
+	UserID     string      `json:"user_id" binding:"required"`
+	ListID     string      `json:"list_id" binding:"required"`
+	VoteStatus *VoteStatus `json:"vote_status" binding:"required"`
+}
+
+// VoteProblemList sets the vote of the user on the list. Voting NoVote clears the vote.
+// The vote record and the list counters are each changed with a single atomic update.
+func (db *MongoDB) VoteProblemList(arg VoteProblemListParams) error {
+	creatorID, err := db.problemListCreator(arg.ListID)
+	if err != nil {
+		return err
+	}
+
+	previousVote := UserListVote{VoteStatus: NoVote}
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_list_votes")
+	filter := bson.M{"user_id": arg.UserID, "list_id": arg.ListID}
+	update := bson.M{"$set": bson.M{"vote_status": *arg.VoteStatus}}
+	updateOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err = collection.FindOneAndUpdate(context.Background(), filter, update, updateOptions).Decode(&previousVote)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	if previousVote.VoteStatus == *arg.VoteStatus {
+		return nil
+	}
+
+	counters := bson.M{}
+	incrementVoteCounter(counters, previousVote.VoteStatus, -1)
+	incrementVoteCounter(counters, *arg.VoteStatus, 1)
+
+	objectID, err := primitive.ObjectIDFromHex(arg.ListID)
+	if err != nil {
+		return err
+	}
 
-	if result.DeletedCount == 0 {
-		return errors.New("list not found")
+	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{"$inc": counters})
+	if err != nil {
+		return err
+	}
+
+	db.evaluateAchievements(creatorID, ListVotedEvent)
+
+	return nil
+}
+
+// problemListCreator returns the creator of the list, checking that the list exists
+// without loading it or resolving its problems.
+func (db *MongoDB) problemListCreator(listID string) (string, error) {
+	objectID, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return "", errors.New("problem list not found")
+	}
+
+	var problemList ProblemList
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	findOptions := options.FindOne().SetProjection(bson.M{"creator_id": 1})
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}, findOptions).Decode(&problemList)
+	if err == mongo.ErrNoDocuments {
+		return "", errors.New("problem list not found")
+	}
+
+	return problemList.CreatorID, err
+}
+
+func incrementVoteCounter(counters bson.M, voteStatus VoteStatus, amount int) {
+	switch voteStatus {
+	case Upvote:
+		counters["upvotes"] = amount
+	case Downvote:
+		counters["downvotes"] = amount
+	}
+}
+
+func (db *MongoDB) GetProblemListVote(listID string, userID string) (VoteStatus, error) {
+	var vote UserListVote
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_list_votes")
+	filter := bson.M{"user_id": userID, "list_id": listID}
+	err := collection.FindOne(context.Background(), filter).Decode(&vote)
+	if err == mongo.ErrNoDocuments {
+		return NoVote, nil
+	}
+
+	return vote.VoteStatus, err
+}
+
+type ReportProblemListParams struct {
+	ListID string `json:"list_id" binding:"required"`
+	UserID string `json:"user_id" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func listReportFromParams(arg ReportProblemListParams) ListReport {
+	return ListReport{
+		ListID:     arg.ListID,
+		UserID:     arg.UserID,
+		Reason:     arg.Reason,
+		ReportedAt: time.Now(),
+	}
+}
+
+// listReportIndexes let each user report a list once, even when the reports are sent concurrently.
+var listReportIndexes = map[string]mongo.IndexModel{
+	"list_reports": {
+		Keys:    bson.D{{Key: "list_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	},
+}
+
+// CreateListReport stores the report unless the user already reported the list.
+func (db *MongoDB) CreateListReport(arg ReportProblemListParams) (ListReport, error) {
+	report := listReportFromParams(arg)
+
+	if _, err := db.problemListCreator(arg.ListID); err != nil {
+		return report, err
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("list_reports")
+	filter := bson.M{"list_id": arg.ListID, "user_id": arg.UserID}
+	update := bson.M{"$setOnInsert": report}
+	options := options.Update().SetUpsert(true)
+	result, err := collection.UpdateOne(context.Background(), filter, update, options)
+	if mongo.IsDuplicateKeyError(err) {
+		return report, errors.New("problem list already reported by user")
+	}
+	if err != nil {
+		return report, err
+	}
+
+	if result.UpsertedCount == 0 {
+		return report, errors.New("problem list already reported by user")
+	}
+
+	report.ID = result.UpsertedID.(primitive.ObjectID).Hex()
+
+	return report, nil
+}
+
+type ReportedProblemList struct {
+	ListID         string    `json:"list_id" bson:"_id"`
+	ReportCount    int       `json:"report_count" bson:"report_count"`
+	Reasons        []string  `json:"reasons" bson:"reasons"`
+	LastReportedAt time.Time `json:"last_reported_at" bson:"last_reported_at"`
+}
+
+// ListReportedProblemLists returns the reported lists, the most reported first.
+func (db *MongoDB) ListReportedProblemLists(pagination PaginationParams) ([]ReportedProblemList, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":              "$list_id",
+			"report_count":     bson.M{"$sum": 1},
+			"reasons":          bson.M{"$push": "$reason"},
+			"last_reported_at": bson.M{"$max": "$reported_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "report_count", Value: -1}, {Key: "last_reported_at", Value: -1}}}},
+		{{Key: "$skip", Value: pagination.Skip}},
+		{{Key: "$limit", Value: pagination.Limit}},
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("list_reports")
+	cursor, err := collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
 	}
 
-	return err
+	reportedLists := make([]ReportedProblemList, 0)
+	err = cursor.All(context.Background(), &reportedLists)
+	return reportedLists, err
 }
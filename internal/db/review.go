@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"math"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReviewDatabase interface {
+	ListReviewQueue(userID string, arg ReviewQueueParams) ([]interface{}, error)
+}
+
+const (
+	initialEase             = 2.5
+	minimumEase             = 1.3
+	defaultDailyReviewLimit = 20
+	// reviewBatchSize is how many due problems are looked up at once when building the queue
+	reviewBatchSize = 50
+)
+
+type reviewSchedule struct {
+	Ease        float64
+	Interval    int
+	Repetitions int
+	DueAt       time.Time
+}
+
+// recallQuality returns the SM-2 quality (0 to 5) of an attempt.
+// The self-rated quality is used when given, otherwise it is derived from the solution accuracy.
+func recallQuality(selfRated *int, solutionAccuracy SolutionAccuracy) int {
+	if selfRated != nil {
+		return *selfRated
+	}
+
+	switch solutionAccuracy {
+	case Correct:
+		return 4
+	case Partial:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// nextReviewSchedule applies the SM-2 algorithm to the review state stored in the history.
+// Interval is measured in days.
+func nextReviewSchedule(history UserProblemHistory, quality int, reviewedAt time.Time) reviewSchedule {
+	ease := history.Ease
+	if ease == 0 {
+		ease = initialEase
+	}
+
+	var interval, repetitions int
+	if quality < 3 {
+		repetitions = 0
+		interval = 1
+	} else {
+		repetitions = history.Repetitions + 1
+		switch repetitions {
+		case 1:
+			interval = 1
+		case 2:
+			interval = 6
+		default:
+			interval = int(math.Round(float64(history.Interval) * ease))
+		}
+	}
+
+	missedQuality := float64(5 - quality)
+	ease += 0.1 - missedQuality*(0.08+missedQuality*0.02)
+	if ease < minimumEase {
+		ease = minimumEase
+	}
+
+	return reviewSchedule{
+		Ease:        ease,
+		Interval:    interval,
+		Repetitions: repetitions,
+		DueAt:       reviewedAt.AddDate(0, 0, interval),
+	}
+}
+
+type ReviewQueueParams struct {
+	SubjectFilter string `form:"subject_id"`
+	DailyLimit    int    `form:"daily_limit" binding:"omitempty,min=1,max=200"`
+}
+
+// ListReviewQueue returns the problems the user is due to review, the most overdue first.
+// Problems answered today while they were due count toward the daily limit,
+// while first solves and practice of problems that are not due do not.
+// The day starts at midnight in the time zone of the user.
+func (db *MongoDB) ListReviewQueue(userID string, arg ReviewQueueParams) ([]interface{}, error) {
+	if arg.DailyLimit == 0 {
+		arg.DailyLimit = defaultDailyReviewLimit
+	}
+
+	user, err := db.GetUser(userID)
+	if err != nil && err != mongo.ErrNoDocuments && err != primitive.ErrInvalidHex {
+		return nil, err
+	}
+
+	now := time.Now()
+	local := now.In(userLocation(user))
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("user_problem_histories")
+	filter := bson.M{"user_id": userID, "last_due_review_at": bson.M{"$gte": startOfDay}}
+	reviewedToday, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := arg.DailyLimit - int(reviewedToday)
+	if remaining <= 0 {
+		return []interface{}{}, nil
+	}
+
+	filter = bson.M{"user_id": userID, "due_at": bson.M{"$lte": now}}
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{{Key: "due_at", Value: 1}})
+	findOptions.SetProjection(bson.M{"problem_id": 1})
+	findOptions.SetBatchSize(reviewBatchSize)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	// due problems are read in batches, since deleted and filtered out problems
+	// are only known once their batch is looked up
+	queue := make([]AnyProblem, 0)
+	batch := make([]primitive.ObjectID, 0, reviewBatchSize)
+	addBatch := func() error {
+		problemsByID, err := db.problemsByID(batch, arg.SubjectFilter, userID)
+		if err != nil {
+			return err
+		}
+		for _, objectID := range batch {
+			if problem, ok := problemsByID[objectID.Hex()]; ok && len(queue) < remaining {
+				queue = append(queue, problem)
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for len(queue) < remaining && cursor.Next(context.Background()) {
+		var history UserProblemHistory
+		if err := cursor.Decode(&history); err != nil {
+			return nil, err
+		}
+
+		objectID, err := primitive.ObjectIDFromHex(history.ProblemID)
+		if err != nil {
+			continue
+		}
+		batch = append(batch, objectID)
+
+		if len(batch) == reviewBatchSize {
+			if err := addBatch(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(batch) > 0 && len(queue) < remaining {
+		if err := addBatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	return db.HideExamAnswers(userID, queue)
+}
+
+// problemsByID returns the problems with the given ids that the user can see, keyed by id.
+func (db *MongoDB) problemsByID(ids []primitive.ObjectID, subjectFilter string, userID string) (map[string]AnyProblem, error) {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problems")
+	filter := visibleProblemsFilter(userID)
+	filter["_id"] = bson.M{"$in": ids}
+	if subjectFilter != "" {
+		filter["subject_id"] = subjectFilter
+	}
+
+	cursor, err := collection.Find(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	problems := make(map[string]AnyProblem)
+	for cursor.Next(context.Background()) {
+		var problem AnyProblem
+		if err := cursor.Decode(&problem); err != nil {
+			return nil, err
+		}
+		problems[problem.ID] = problem
+	}
+
+	return problems, cursor.Err()
+}
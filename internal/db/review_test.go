@@ -0,0 +1,94 @@
+package db
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRecallQuality(t *testing.T) {
+	selfRated := 5
+
+	tests := []struct {
+		name             string
+		selfRated        *int
+		solutionAccuracy SolutionAccuracy
+		expected         int
+	}{
+		{"self rated", &selfRated, Incorrect, 5},
+		{"correct", nil, Correct, 4},
+		{"partial", nil, Partial, 3},
+		{"incorrect", nil, Incorrect, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quality := recallQuality(test.selfRated, test.solutionAccuracy)
+			if quality != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, quality)
+			}
+		})
+	}
+}
+
+func TestNextReviewSchedule(t *testing.T) {
+	reviewedAt := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		history  UserProblemHistory
+		quality  int
+		expected reviewSchedule
+	}{
+		{
+			name:     "first review starts with the initial ease",
+			history:  UserProblemHistory{},
+			quality:  4,
+			expected: reviewSchedule{Ease: 2.5, Interval: 1, Repetitions: 1},
+		},
+		{
+			name:     "second review waits six days",
+			history:  UserProblemHistory{Ease: 2.5, Interval: 1, Repetitions: 1},
+			quality:  5,
+			expected: reviewSchedule{Ease: 2.6, Interval: 6, Repetitions: 2},
+		},
+		{
+			name:     "later reviews multiply the interval by the ease",
+			history:  UserProblemHistory{Ease: 2.5, Interval: 6, Repetitions: 2},
+			quality:  4,
+			expected: reviewSchedule{Ease: 2.5, Interval: 15, Repetitions: 3},
+		},
+		{
+			name:     "a failed review starts over",
+			history:  UserProblemHistory{Ease: 2.5, Interval: 15, Repetitions: 3},
+			quality:  1,
+			expected: reviewSchedule{Ease: 1.96, Interval: 1, Repetitions: 0},
+		},
+		{
+			name:     "the ease never drops below the minimum",
+			history:  UserProblemHistory{Ease: 1.3, Interval: 1, Repetitions: 0},
+			quality:  0,
+			expected: reviewSchedule{Ease: 1.3, Interval: 1, Repetitions: 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule := nextReviewSchedule(test.history, test.quality, reviewedAt)
+			test.expected.DueAt = reviewedAt.AddDate(0, 0, test.expected.Interval)
+
+			if math.Abs(schedule.Ease-test.expected.Ease) > 1e-9 {
+				t.Errorf("expected ease %v, got %v", test.expected.Ease, schedule.Ease)
+			}
+			if schedule.Interval != test.expected.Interval {
+				t.Errorf("expected interval %v, got %v", test.expected.Interval, schedule.Interval)
+			}
+			if schedule.Repetitions != test.expected.Repetitions {
+				t.Errorf("expected repetitions %v, got %v", test.expected.Repetitions, schedule.Repetitions)
+			}
+			if !schedule.DueAt.Equal(test.expected.DueAt) {
+				t.Errorf("expected due at %v, got %v", test.expected.DueAt, schedule.DueAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SolveLaterDatabase interface {
+	AddSolveLaterProblem(userID string, problemID string) error
+	RemoveSolveLaterProblem(userID string, problemID string) error
+	ListSolveLaterProblems(userID string, pagination PaginationParams) ([]SolveLaterProblem, error)
+
+	AddSolveLaterList(userID string, listID string) error
+	RemoveSolveLaterList(userID string, listID string) error
+	ListSolveLaterLists(userID string, pagination PaginationParams) ([]ProblemList, error)
+}
+
+// AddSolveLaterProblem bookmarks the problem. Bookmarking a problem again clears its done state.
+func (db *MongoDB) AddSolveLaterProblem(userID string, problemID string) error {
+	if _, err := db.GetVisibleProblem(problemID, userID); err != nil {
+		return err
+	}
+
+	return db.updateSolveLater(userID, bson.M{
+		"$addToSet": bson.M{"solve_later_problems": problemID},
+		"$pull":     bson.M{"solve_later_done": problemID},
+	})
+}
+
+func (db *MongoDB) RemoveSolveLaterProblem(userID string, problemID string) error {
+	return db.updateSolveLater(userID, bson.M{
+		"$pull": bson.M{"solve_later_problems": problemID, "solve_later_done": problemID},
+	})
+}
+
+func (db *MongoDB) AddSolveLaterList(userID string, listID string) error {
+	if _, err := db.GetVisibleProblemList(listID, userID); err != nil {
+		return err
+	}
+
+	return db.updateSolveLater(userID, bson.M{"$addToSet": bson.M{"solve_later_lists": listID}})
+}
+
+func (db *MongoDB) RemoveSolveLaterList(userID string, listID string) error {
+	return db.updateSolveLater(userID, bson.M{"$pull": bson.M{"solve_later_lists": listID}})
+}
+
+func (db *MongoDB) updateSolveLater(userID string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// markSolveLaterDone marks the problem as done if the user bookmarked it.
+func (db *MongoDB) markSolveLaterDone(userID string, problemID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter := bson.M{"_id": objectID, "solve_later_problems": problemID}
+	update := bson.M{"$addToSet": bson.M{"solve_later_done": problemID}}
+	_, err = collection.UpdateOne(context.Background(), filter, update)
+
+	return err
+}
+
+type SolveLaterProblem struct {
+	Problem StrippedProblem `json:"problem"`
+	Done    bool            `json:"done"`
+}
+
+// solveLaterPage returns the bookmarks of the user in the given field, only reading the requested page.
+func (db *MongoDB) solveLaterPage(userID string, field string, pagination PaginationParams) (User, error) {
+	var user User
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return user, errors.New("user not found")
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	projection := bson.M{
+		field:              bson.M{"$slice": bson.A{pagination.Skip, pagination.Limit}},
+		"solve_later_done": 1,
+	}
+	findOptions := options.FindOne().SetProjection(projection)
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}, findOptions).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return user, errors.New("user not found")
+	}
+
+	return user, err
+}
+
+// ListSolveLaterProblems returns a page of the bookmarked problems, in the order they were bookmarked.
+// Problems that were deleted or that the user can no longer see are left out.
+func (db *MongoDB) ListSolveLaterProblems(userID string, pagination PaginationParams) ([]SolveLaterProblem, error) {
+	user, err := db.solveLaterPage(userID, "solve_later_problems", pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	var problemIDs []primitive.ObjectID
+	for _, problemID := range user.SolveLaterProblems {
+		if objectID, err := primitive.ObjectIDFromHex(problemID); err == nil {
+			problemIDs = append(problemIDs, objectID)
+		}
+	}
+
+	bookmarks := make([]SolveLaterProblem, 0)
+	if len(problemIDs) == 0 {
+		return bookmarks, nil
+	}
+
+	problems, err := db.problemsByID(problemIDs, "", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, problemID := range user.SolveLaterProblems {
+		problem, ok := problems[problemID]
+		if !ok {
+			continue
+		}
+
+		bookmarks = append(bookmarks, SolveLaterProblem{
+			Problem: strippedProblem(problem),
+			Done:    containsString(user.SolveLaterDone, problemID),
+		})
+	}
+
+	return bookmarks, nil
+}
+
+// ListSolveLaterLists returns a page of the bookmarked lists, in the order they were bookmarked.
+func (db *MongoDB) ListSolveLaterLists(userID string, pagination PaginationParams) ([]ProblemList, error) {
+	user, err := db.solveLaterPage(userID, "solve_later_lists", pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	var listIDs []primitive.ObjectID
+	for _, listID := range user.SolveLaterLists {
+		if objectID, err := primitive.ObjectIDFromHex(listID); err == nil {
+			listIDs = append(listIDs, objectID)
+		}
+	}
+
+	problemLists := make([]ProblemList, 0)
+	if len(listIDs) == 0 {
+		return problemLists, nil
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problemlists")
+	cursor, err := collection.Find(context.Background(), bson.M{"_id": bson.M{"$in": listIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ProblemList
+	if err := cursor.All(context.Background(), &found); err != nil {
+		return nil, err
+	}
+
+	listsByID := make(map[string]ProblemList)
+	for _, list := range found {
+		listsByID[list.ID] = list
+	}
+
+	for _, listID := range user.SolveLaterLists {
+		list, ok := listsByID[listID]
+		if !ok || !canViewList(list, userID) {
+			continue
+		}
+
+		if err := db.resolveDynamicList(&list); err != nil {
+			return nil, err
+		}
+		problemLists = append(problemLists, list)
+	}
+
+	return problemLists, nil
+}
@@ -0,0 +1,322 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"time"
+	_ "time/tzdata"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StreakDatabase interface {
+	GetStreak(userID string) (StreakState, error)
+	SetDailyGoal(arg SetDailyGoalParams, userID string) error
+}
+
+const dayLayout = "2006-01-02"
+
+func userLocation(user User) *time.Location {
+	location, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func dailyGoalTarget(goal DailyGoal) int {
+	if goal.Target < 1 {
+		return 1
+	}
+	return goal.Target
+}
+
+func dailyGoalReached(goal DailyGoal, progress DailyProgress) bool {
+	if goal.Type == CorrectAnswersGoal {
+		return progress.CorrectAnswers >= dailyGoalTarget(goal)
+	}
+	return progress.Problems >= dailyGoalTarget(goal)
+}
+
+// daysBetween returns how many days go from the first day to the second one.
+// Both days use the dayLayout format.
+func daysBetween(from string, to string) int {
+	fromDay, err := time.Parse(dayLayout, from)
+	if err != nil {
+		return 0
+	}
+	toDay, err := time.Parse(dayLayout, to)
+	if err != nil {
+		return 0
+	}
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+// advanceStreak returns the streak after the daily goal was reached on the given day.
+// Missed days in between are covered by the remaining streak freezes.
+func advanceStreak(streak Streak, goal DailyGoal, day string) Streak {
+	if streak.LastCompletedDay == "" {
+		streak.Current = 1
+		streak.FreezesUsed = 0
+	} else {
+		missedDays := daysBetween(streak.LastCompletedDay, day) - 1
+		if missedDays < 0 {
+			return streak
+		}
+
+		if missedDays <= goal.StreakFreezes-streak.FreezesUsed {
+			streak.Current++
+			streak.FreezesUsed += missedDays
+		} else {
+			streak.Current = 1
+			streak.FreezesUsed = 0
+		}
+	}
+
+	streak.LastCompletedDay = day
+	if streak.Current > streak.Longest {
+		streak.Longest = streak.Current
+	}
+
+	return streak
+}
+
+// streakAsOf returns the streak as seen on the given day.
+// A streak that can no longer be continued is reported as zero.
+func streakAsOf(streak Streak, goal DailyGoal, day string) Streak {
+	if streak.LastCompletedDay == "" {
+		return streak
+	}
+
+	missedDays := daysBetween(streak.LastCompletedDay, day) - 1
+	if missedDays > goal.StreakFreezes-streak.FreezesUsed {
+		streak.Current = 0
+	}
+
+	return streak
+}
+
+// updateStreak adds the attempt to the daily progress of the user and extends their streak
+// when the attempt makes them reach the daily goal.
+func (db *MongoDB) updateStreak(attempt ProblemAttempt) error {
+	user, err := db.GetUser(attempt.UserID)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	day := attempt.AttemptedAt.In(userLocation(user)).Format(dayLayout)
+
+	correctAnswers := 0
+	if attempt.SolutionAccuracy == Correct {
+		correctAnswers = 1
+	}
+
+	var progress DailyProgress
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("daily_progress")
+	filter := bson.M{"user_id": attempt.UserID, "day": day}
+	update := bson.M{"$inc": bson.M{"problems": 1, "correct_answers": correctAnswers}}
+	updateOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = collection.FindOneAndUpdate(context.Background(), filter, update, updateOptions).Decode(&progress)
+	if err != nil {
+		return err
+	}
+
+	if !dailyGoalReached(user.DailyGoal, progress) || user.Streak.LastCompletedDay == day {
+		return nil
+	}
+
+	streak := advanceStreak(user.Streak, user.DailyGoal, day)
+
+	objectID, err := primitive.ObjectIDFromHex(attempt.UserID)
+	if err != nil {
+		return err
+	}
+
+	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter = bson.M{"_id": objectID, "streak.last_completed_day": user.Streak.LastCompletedDay}
+	if user.Streak.LastCompletedDay == "" {
+		// users created before streaks existed have no streak field at all
+		filter["streak.last_completed_day"] = bson.M{"$in": bson.A{"", nil}}
+	}
+	_, err = collection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"streak": streak}})
+
+	return err
+}
+
+type StreakState struct {
+	DailyGoal DailyGoal     `json:"daily_goal"`
+	TimeZone  string        `json:"time_zone"`
+	Streak    Streak        `json:"streak"`
+	Today     DailyProgress `json:"today"`
+	GoalMet   bool          `json:"goal_met"`
+}
+
+func (db *MongoDB) GetStreak(userID string) (StreakState, error) {
+	var state StreakState
+
+	user, err := db.GetUser(userID)
+	if err != nil {
+		return state, err
+	}
+
+	today := time.Now().In(userLocation(user)).Format(dayLayout)
+	state.DailyGoal = user.DailyGoal
+	state.TimeZone = user.TimeZone
+	state.Streak = user.Streak
+	state.Today = DailyProgress{UserID: userID, Day: today}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("daily_progress")
+	filter := bson.M{"user_id": userID, "day": today}
+	err = collection.FindOne(context.Background(), filter).Decode(&state.Today)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return state, err
+	}
+
+	state.GoalMet = dailyGoalReached(user.DailyGoal, state.Today)
+
+	return state, nil
+}
+
+type SetDailyGoalParams struct {
+	Type          DailyGoalType `json:"type" binding:"required,daily_goal_type"`
+	Target        int           `json:"target" binding:"required,min=1,max=100"`
+	TimeZone      string        `json:"time_zone" binding:"required,timezone"`
+	StreakFreezes int           `json:"streak_freezes" binding:"min=0,max=7"`
+}
+
+func (db *MongoDB) SetDailyGoal(arg SetDailyGoalParams, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	goal := DailyGoal{
+		Type:          arg.Type,
+		Target:        arg.Target,
+		StreakFreezes: arg.StreakFreezes,
+	}
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{
+		"daily_goal": goal,
+		"time_zone":  arg.TimeZone,
+	}}
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// BackfillStreaks rebuilds the daily progress and the streak of every user from their problem attempts.
+func (db *MongoDB) BackfillStreaks() error {
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	cursor, err := collection.Find(context.Background(), bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var user User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+
+		if err := db.backfillUserStreak(user); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (db *MongoDB) backfillUserStreak(user User) error {
+	location := userLocation(user)
+
+	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("problem_attempts")
+	filter := bson.M{"user_id": user.ID}
+	findOptions := options.Find().SetProjection(bson.M{"attempted_at": 1, "solution_accuracy": 1})
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	progressByDay := make(map[string]*DailyProgress)
+	for cursor.Next(context.Background()) {
+		var attempt ProblemAttempt
+		if err := cursor.Decode(&attempt); err != nil {
+			return err
+		}
+
+		day := attempt.AttemptedAt.In(location).Format(dayLayout)
+		progress, ok := progressByDay[day]
+		if !ok {
+			progress = &DailyProgress{UserID: user.ID, Day: day}
+			progressByDay[day] = progress
+		}
+
+		progress.Problems++
+		if attempt.SolutionAccuracy == Correct {
+			progress.CorrectAnswers++
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	days := make([]string, 0, len(progressByDay))
+	for day := range progressByDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	var streak Streak
+	var models []mongo.WriteModel
+	for _, day := range days {
+		progress := progressByDay[day]
+		if dailyGoalReached(user.DailyGoal, *progress) {
+			streak = advanceStreak(streak, user.DailyGoal, day)
+		}
+
+		filter := bson.M{"user_id": user.ID, "day": day}
+		update := bson.M{"$set": bson.M{
+			"problems":        progress.Problems,
+			"correct_answers": progress.CorrectAnswers,
+		}}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	if len(models) > 0 {
+		collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("daily_progress")
+		if _, err := collection.BulkWrite(context.Background(), models); err != nil {
+			return err
+		}
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return err
+	}
+
+	collection = db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
+	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{"$set": bson.M{"streak": streak}})
+
+	return err
+}
@@ -38,6 +38,10 @@ func userFromParams(arg CreateUserParams) User {
 		CreatedLists:       []string{},
 		SolveLaterProblems: []string{},
 		SolveLaterLists:    []string{},
+		SolveLaterDone:     []string{},
+
+		DailyGoal: DailyGoal{Type: ProblemsGoal, Target: 1},
+		TimeZone:  "UTC",
 	}
 }
 
@@ -77,8 +81,17 @@ func (db *MongoDB) GetUser(id string) (User, error) {
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
 	filter := bson.D{{Key: "_id", Value: objectID}}
 	err = collection.FindOne(context.Background(), filter).Decode(&user)
+	if err != nil {
+		return user, err
+	}
 
-	return user, err
+	return userWithCurrentStreak(user), nil
+}
+
+func userWithCurrentStreak(user User) User {
+	today := time.Now().In(userLocation(user)).Format(dayLayout)
+	user.Streak = streakAsOf(user.Streak, user.DailyGoal, today)
+	return user
 }
 
 type UpdateUserParams struct {
@@ -148,6 +161,9 @@ func (db *MongoDB) GetUserByEmail(arg GetUserByEmailParams) (User, error) {
 	collection := db.client.Database(os.Getenv("MONGODB_DB_NAME")).Collection("users")
 	filter := bson.D{{Key: "email", Value: arg.Email}}
 	err := collection.FindOne(context.Background(), filter).Decode(&user)
+	if err != nil {
+		return user, err
+	}
 
-	return user, err
+	return userWithCurrentStreak(user), nil
 }
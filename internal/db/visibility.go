@@ -0,0 +1,98 @@
+package db
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Problems and lists saved before visibility existed have none and are public.
+
+// listedVisibility matches the documents that can appear in listings and searches.
+func listedVisibility() bson.M {
+	return bson.M{"$nin": []Visibility{Unlisted, Private}}
+}
+
+// visibleProblemsFilter matches the problems the user can see by id.
+func visibleProblemsFilter(userID string) bson.M {
+	notPrivate := bson.M{"visibility": bson.M{"$ne": Private}}
+	if userID == "" {
+		return notPrivate
+	}
+	return bson.M{"$or": bson.A{notPrivate, bson.M{"creator_id": userID}}}
+}
+
+// canViewProblem reports whether the user can see the problem. Private problems are only seen by their creator.
+func canViewProblem(problem Problem, userID string) bool {
+	return problem.Visibility != Private || (userID != "" && problem.CreatorID == userID)
+}
+
+// canViewList reports whether the user can see the list. Private lists are only seen by their owner and collaborators.
+func canViewList(problemList ProblemList, userID string) bool {
+	return problemList.Visibility != Private || listRole(problemList, userID).Includes(ViewerRole)
+}
+
+// GetVisibleProblem returns the problem if the user can see it.
+// A problem the user cannot see is reported as not found, so its existence is not leaked.
+func (db *MongoDB) GetVisibleProblem(id string, userID string) (AnyProblem, error) {
+	problem, err := db.GetProblem(id)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return AnyProblem{}, errors.New("problem not found")
+	}
+	if err != nil {
+		return AnyProblem{}, err
+	}
+
+	if !canViewProblem(problem.Problem, userID) {
+		return AnyProblem{}, errors.New("problem not found")
+	}
+
+	return problem, nil
+}
+
+// GetVisibleProblemList returns the list if the user can see it.
+func (db *MongoDB) GetVisibleProblemList(id string, userID string) (ProblemList, error) {
+	problemList, err := db.GetProblemList(id)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return ProblemList{}, errors.New("problem list not found")
+	}
+	if err != nil {
+		return ProblemList{}, err
+	}
+
+	if !canViewList(problemList, userID) {
+		return ProblemList{}, errors.New("problem list not found")
+	}
+
+	return problemList, nil
+}
+
+// visibleProblemIDs keeps the problems the user can see, in the same order.
+func (db *MongoDB) visibleProblemIDs(problemIDs []string, userID string) ([]string, error) {
+	var objectIDs []primitive.ObjectID
+	for _, problemID := range problemIDs {
+		if objectID, err := primitive.ObjectIDFromHex(problemID); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	visible := make([]string, 0, len(objectIDs))
+	if len(objectIDs) == 0 {
+		return visible, nil
+	}
+
+	problems, err := db.problemsByID(objectIDs, "", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, problemID := range problemIDs {
+		if _, ok := problems[problemID]; ok {
+			visible = append(visible, problemID)
+		}
+	}
+
+	return visible, nil
+}
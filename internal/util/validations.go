@@ -15,7 +15,15 @@ func IsSupportedLanguage(language string) bool {
 
 func IsFieldToOrderProblems(field string) bool {
 	switch field {
-	case "created_at", "attempts", "accuracy", "upvotes":
+	case "created_at", "attempts", "accuracy", "upvotes", "first_attempts", "first_attempt_accuracy":
+		return true
+	}
+	return false
+}
+
+func IsFieldToOrderLists(field string) bool {
+	switch field {
+	case "created_at", "upvotes", "fork_count":
 		return true
 	}
 	return false
@@ -28,3 +36,67 @@ func IsLevelOfEducation(level string) bool {
 	}
 	return false
 }
+
+func IsLeaderboardScope(scope string) bool {
+	switch scope {
+	case "global", "subject", "topic", "":
+		return true
+	}
+	return false
+}
+
+func IsLeaderboardWindow(window string) bool {
+	switch window {
+	case "weekly", "monthly", "all_time", "":
+		return true
+	}
+	return false
+}
+
+func IsLeaderboardMetric(metric string) bool {
+	switch metric {
+	case "correct_first_attempts", "score", "":
+		return true
+	}
+	return false
+}
+
+func IsDailyGoalType(goalType string) bool {
+	switch goalType {
+	case "problems", "correct_answers":
+		return true
+	}
+	return false
+}
+
+func IsExportFormat(format string) bool {
+	switch format {
+	case "csv", "jsonl":
+		return true
+	}
+	return false
+}
+
+func IsRevealAnswers(reveal string) bool {
+	switch reveal {
+	case "immediately", "after_submission":
+		return true
+	}
+	return false
+}
+
+func IsCollaboratorRole(role string) bool {
+	switch role {
+	case "editor", "viewer":
+		return true
+	}
+	return false
+}
+
+func IsVisibility(visibility string) bool {
+	switch visibility {
+	case "public", "unlisted", "private":
+		return true
+	}
+	return false
+}